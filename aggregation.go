@@ -0,0 +1,298 @@
+package geohash
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// defaults of Elasticsearch grid aggregations
+const (
+	DefaultGeoHashGridPrecision = 5
+	DefaultGeoTileGridPrecision = 7
+	DefaultGridSize             = 10000
+	MaxGeoTileZoom              = 29
+)
+
+// GeoPoint is a location of a document, JSON form follows Elasticsearch geo_point
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lon"`
+}
+
+// GridOptions configures GeoHashGrid and GeoTileGrid
+type GridOptions struct {
+	// Precision is geohash length in [1, 12] or tile zoom in [0, 29],
+	// it is used as is, see DefaultGeoHashGridPrecision and DefaultGeoTileGridPrecision
+	Precision int
+	// Size is the maximum number of buckets returned, DefaultGridSize if 0
+	Size int
+	// ShardSize is the maximum number of buckets each shard returns before reduce,
+	// Size*1.5+10 if 0, and never less than Size
+	ShardSize int
+	// CentroidName names geo_centroid sub aggregation of buckets, omitted if empty
+	CentroidName string
+	// BoundsName names geo_bounds sub aggregation of buckets, omitted if empty
+	BoundsName string
+}
+
+// GridResult is the response of a grid aggregation
+type GridResult struct {
+	Buckets []GridBucket `json:"buckets"`
+}
+
+// GridBucket is a single cell of grid aggregation
+type GridBucket struct {
+	Key      string
+	DocCount int64
+	Centroid GeoCentroid
+	Bounds   GeoBounds
+
+	order        int64
+	centroidName string
+	boundsName   string
+}
+
+// MarshalJSON renders bucket with sub aggregations under their names
+func (gb GridBucket) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	key, _ := json.Marshal(gb.Key)
+	fmt.Fprintf(buf, `{"key":%s,"doc_count":%d`, key, gb.DocCount)
+	subs := []struct {
+		name string
+		v    interface{}
+	}{{gb.centroidName, gb.Centroid}, {gb.boundsName, gb.Bounds}}
+	for _, s := range subs {
+		if s.name == "" {
+			continue
+		}
+		name, _ := json.Marshal(s.name)
+		v, err := json.Marshal(s.v)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(buf, `,%s:%s`, name, v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// GeoHashGrid buckets points of every shard by geohash as Elasticsearch geohash_grid,
+// each shard keeps its top ShardSize buckets before they are merged and cut to Size,
+// so doc counts of large results may be approximate just like Elasticsearch
+func GeoHashGrid(opt GridOptions, shards ...[]GeoPoint) (GridResult, error) {
+	if opt.Precision < 1 || opt.Precision > 12 {
+		return GridResult{}, fmt.Errorf("invalid geohash aggregation precision of %d, must be between 1 and 12", opt.Precision)
+	}
+	gh := NewDefaultGeoHash()
+	return grid(opt, shards, func(p GeoPoint) (string, int64) {
+		key := gh.Encode(p.Lat, p.Lng, opt.Precision)
+		order, _ := ESLongEncode(key)
+		return key, order
+	})
+}
+
+// GeoTileGrid buckets points of every shard by web mercator tile "zoom/x/y"
+// as Elasticsearch geotile_grid, truncation follows GeoHashGrid
+func GeoTileGrid(opt GridOptions, shards ...[]GeoPoint) (GridResult, error) {
+	if opt.Precision < 0 || opt.Precision > MaxGeoTileZoom {
+		return GridResult{}, fmt.Errorf("invalid geotile_grid precision of %d, must be between 0 and %d", opt.Precision, MaxGeoTileZoom)
+	}
+	zoom := opt.Precision
+	return grid(opt, shards, func(p GeoPoint) (string, int64) {
+		x, y := tileOf(p.Lat, p.Lng, zoom)
+		key := strconv.Itoa(zoom) + "/" + strconv.Itoa(x) + "/" + strconv.Itoa(y)
+		return key, int64(zoom)<<58 | int64(x)<<MaxGeoTileZoom | int64(y)
+	})
+}
+
+// tileOf returns web mercator tile of point at zoom, latitudes beyond
+// mercator limits fall into the first or last row
+func tileOf(lat, lng float64, zoom int) (x, y int) {
+	tiles := float64(uint64(1) << uint(zoom))
+	clamp := func(v float64) int {
+		return int(math.Max(0, math.Min(math.Floor(v), tiles-1)))
+	}
+	sin := math.Sin(lat * math.Pi / 180)
+	x = clamp((lng + 180) / 360 * tiles)
+	y = clamp((0.5 - math.Log((1+sin)/(1-sin))/(4*math.Pi)) * tiles)
+	return x, y
+}
+
+func grid(opt GridOptions, shards [][]GeoPoint, keyOf func(GeoPoint) (string, int64)) (GridResult, error) {
+	size, shardSize := opt.Size, opt.ShardSize
+	if size < 0 || shardSize < 0 {
+		return GridResult{}, fmt.Errorf("size and shard size must be greater than 0")
+	}
+	if size == 0 {
+		size = DefaultGridSize
+	}
+	if shardSize == 0 {
+		shardSize = int(math.Min(float64(size)*1.5+10, math.MaxInt32))
+	}
+	if shardSize < size {
+		shardSize = size
+	}
+
+	merged := map[string]*GridBucket{}
+	for _, points := range shards {
+		buckets := map[string]*GridBucket{}
+		for _, p := range points {
+			if p.Lat > MaxLat || p.Lat < MinLat || p.Lng > MaxLng || p.Lng < MinLng || p.Lat != p.Lat || p.Lng != p.Lng {
+				return GridResult{}, fmt.Errorf("invalid point lat: %v, lon: %v", p.Lat, p.Lng)
+			}
+			key, order := keyOf(p)
+			b, ok := buckets[key]
+			if !ok {
+				b = &GridBucket{Key: key, order: order, Bounds: GeoBounds{WrapLongitude: true},
+					centroidName: opt.CentroidName, boundsName: opt.BoundsName}
+				buckets[key] = b
+			}
+			b.DocCount++
+			b.Centroid.Add(p)
+			b.Bounds.Add(p)
+		}
+		for _, b := range topBuckets(buckets, shardSize) {
+			if m, ok := merged[b.Key]; ok {
+				m.DocCount += b.DocCount
+				m.Centroid.Merge(b.Centroid)
+				m.Bounds.Merge(b.Bounds)
+			} else {
+				merged[b.Key] = b
+			}
+		}
+	}
+
+	r := GridResult{Buckets: []GridBucket{}}
+	for _, b := range topBuckets(merged, size) {
+		r.Buckets = append(r.Buckets, *b)
+	}
+	return r, nil
+}
+
+// topBuckets returns at most n buckets ordered by doc count,
+// ties are ordered by descending long encoded cell as Elasticsearch does
+func topBuckets(buckets map[string]*GridBucket, n int) []*GridBucket {
+	r := make([]*GridBucket, 0, len(buckets))
+	for _, b := range buckets {
+		r = append(r, b)
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].DocCount != r[j].DocCount {
+			return r[i].DocCount > r[j].DocCount
+		}
+		return r[i].order > r[j].order
+	})
+	if len(r) > n {
+		r = r[:n]
+	}
+	return r
+}
+
+// GeoCentroid is the mean location of points as Elasticsearch geo_centroid
+type GeoCentroid struct {
+	Location GeoPoint `json:"location"`
+	Count    int64    `json:"count"`
+}
+
+// Add updates centroid with point
+func (gc *GeoCentroid) Add(p GeoPoint) {
+	gc.Merge(GeoCentroid{Location: p, Count: 1})
+}
+
+// Merge combines centroid of other points
+func (gc *GeoCentroid) Merge(o GeoCentroid) {
+	if o.Count == 0 {
+		return
+	}
+	n := gc.Count + o.Count
+	w := float64(o.Count) / float64(n)
+	gc.Location.Lat += (o.Location.Lat - gc.Location.Lat) * w
+	gc.Location.Lng += (o.Location.Lng - gc.Location.Lng) * w
+	gc.Count = n
+}
+
+// MarshalJSON omits location of empty centroid
+func (gc GeoCentroid) MarshalJSON() ([]byte, error) {
+	if gc.Count == 0 {
+		return []byte(`{"count":0}`), nil
+	}
+	type centroid GeoCentroid
+	return json.Marshal(centroid(gc))
+}
+
+// GeoBounds is the bounding box of points as Elasticsearch geo_bounds,
+// with WrapLongitude the box may cross the antimeridian when it is narrower
+type GeoBounds struct {
+	WrapLongitude bool
+
+	count                                int64
+	top, bottom                          float64
+	posLeft, posRight, negLeft, negRight float64
+}
+
+// Add extends bounds with point
+func (gb *GeoBounds) Add(p GeoPoint) {
+	o := GeoBounds{count: 1, top: p.Lat, bottom: p.Lat,
+		posLeft: math.Inf(1), posRight: math.Inf(-1), negLeft: math.Inf(1), negRight: math.Inf(-1)}
+	if p.Lng >= 0 {
+		o.posLeft, o.posRight = p.Lng, p.Lng
+	} else {
+		o.negLeft, o.negRight = p.Lng, p.Lng
+	}
+	gb.Merge(o)
+}
+
+// Merge extends bounds with bounds of other points
+func (gb *GeoBounds) Merge(o GeoBounds) {
+	if o.count == 0 {
+		return
+	}
+	if gb.count == 0 {
+		o.WrapLongitude = gb.WrapLongitude
+		*gb = o
+		return
+	}
+	gb.count += o.count
+	gb.top, gb.bottom = math.Max(gb.top, o.top), math.Min(gb.bottom, o.bottom)
+	gb.posLeft, gb.posRight = math.Min(gb.posLeft, o.posLeft), math.Max(gb.posRight, o.posRight)
+	gb.negLeft, gb.negRight = math.Min(gb.negLeft, o.negLeft), math.Max(gb.negRight, o.negRight)
+}
+
+// Corners returns top left and bottom right corners, ok is false for empty bounds
+func (gb GeoBounds) Corners() (topLeft, bottomRight GeoPoint, ok bool) {
+	if gb.count == 0 {
+		return topLeft, bottomRight, false
+	}
+	left, right := gb.negLeft, gb.posRight
+	switch {
+	case math.IsInf(gb.posLeft, 1):
+		left, right = gb.negLeft, gb.negRight
+	case math.IsInf(gb.negLeft, 1):
+		left, right = gb.posLeft, gb.posRight
+	case gb.WrapLongitude:
+		if gb.posRight-gb.negLeft > (MaxLng-gb.posLeft)-(MinLng-gb.negRight) {
+			left, right = gb.posLeft, gb.negRight
+		}
+	}
+	return GeoPoint{Lat: gb.top, Lng: left}, GeoPoint{Lat: gb.bottom, Lng: right}, true
+}
+
+// MarshalJSON renders bounds as Elasticsearch, empty bounds become {}
+func (gb GeoBounds) MarshalJSON() ([]byte, error) {
+	tl, br, ok := gb.Corners()
+	if !ok {
+		return []byte(`{}`), nil
+	}
+	v := struct {
+		Bounds struct {
+			TopLeft     GeoPoint `json:"top_left"`
+			BottomRight GeoPoint `json:"bottom_right"`
+		} `json:"bounds"`
+	}{}
+	v.Bounds.TopLeft, v.Bounds.BottomRight = tl, br
+	return json.Marshal(v)
+}
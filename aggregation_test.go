@@ -0,0 +1,136 @@
+package geohash
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// museums of Elasticsearch aggregation documentation
+var museums = []GeoPoint{
+	{52.374081, 4.912350}, {52.369219, 4.901618}, {52.371667, 4.914722},
+	{48.861111, 2.336389}, {48.860000, 2.327000}, {51.222900, 4.405200},
+}
+
+func bucketCounts(r GridResult) map[string]int64 {
+	m := map[string]int64{}
+	for _, b := range r.Buckets {
+		m[b.Key] = b.DocCount
+	}
+	return m
+}
+
+func TestGeoHashGrid(t *testing.T) {
+	r, err := GeoHashGrid(GridOptions{Precision: 3}, museums)
+	keys := []string{}
+	for _, b := range r.Buckets {
+		keys = append(keys, b.Key)
+	}
+	exp := []string{"u17", "u09", "u15"}
+	if err != nil || !reflect.DeepEqual(exp, keys) || r.Buckets[0].DocCount != 3 || r.Buckets[1].DocCount != 2 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, exp, r, err)
+		t.FailNow()
+	}
+
+	// each shard keeps only its top bucket, u09 is dropped on first shard
+	shard1 := []GeoPoint{museums[0], museums[1], museums[3]}
+	shard2 := []GeoPoint{museums[3], museums[4], museums[5]}
+	r, _ = GeoHashGrid(GridOptions{Precision: 3, Size: 1, ShardSize: 1}, shard1, shard2)
+	if got := bucketCounts(r); !reflect.DeepEqual(map[string]int64{"u17": 2}, got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %#v\n\n", filepath.Base(file), line, got)
+		t.FailNow()
+	}
+	r, _ = GeoHashGrid(GridOptions{Precision: 3, Size: 1}, shard1, shard2)
+	if got := bucketCounts(r); !reflect.DeepEqual(map[string]int64{"u09": 3}, got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %#v\n\n", filepath.Base(file), line, got)
+		t.FailNow()
+	}
+
+	for _, opt := range []GridOptions{{Precision: 0}, {Precision: 13}, {Precision: 3, Size: -1}} {
+		if _, err := GeoHashGrid(opt, museums); err == nil {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\n\texp error of %#v\n\n", filepath.Base(file), line, opt)
+			t.FailNow()
+		}
+	}
+	if _, err := GeoHashGrid(GridOptions{Precision: 3}, []GeoPoint{{91, 0}}); err == nil {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp error of invalid point\n\n", filepath.Base(file), line)
+		t.FailNow()
+	}
+}
+
+func TestGeoTileGrid(t *testing.T) {
+	r, err := GeoTileGrid(GridOptions{Precision: 8}, museums)
+	exp := map[string]int64{"8/131/84": 3, "8/129/88": 2, "8/131/85": 1}
+	if got := bucketCounts(r); err != nil || !reflect.DeepEqual(exp, got) || r.Buckets[0].Key != "8/131/84" {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, exp, got, err)
+		t.FailNow()
+	}
+
+	r, _ = GeoTileGrid(GridOptions{Precision: 1}, []GeoPoint{{90, -180}, {-90, 180}})
+	if got := bucketCounts(r); !reflect.DeepEqual(map[string]int64{"1/0/0": 1, "1/1/1": 1}, got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %#v\n\n", filepath.Base(file), line, got)
+		t.FailNow()
+	}
+}
+
+func TestGridBucketJSON(t *testing.T) {
+	r, _ := GeoHashGrid(GridOptions{Precision: 1, CentroidName: "centroid", BoundsName: "cell"},
+		[]GeoPoint{{1, 2}, {3, 4}})
+	got, err := json.Marshal(r)
+	exp := `{"buckets":[{"key":"s","doc_count":2,"centroid":{"location":{"lat":2,"lon":3},"count":2},` +
+		`"cell":{"bounds":{"top_left":{"lat":3,"lon":2},"bottom_right":{"lat":1,"lon":4}}}}]}`
+	if err != nil || exp != string(got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %s\n\n\tgot: %s\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+
+	r, _ = GeoHashGrid(GridOptions{Precision: 1})
+	if got, _ := json.Marshal(r); string(got) != `{"buckets":[]}` {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %s\n\n", filepath.Base(file), line, got)
+		t.FailNow()
+	}
+}
+
+func TestGeoBounds(t *testing.T) {
+	tr := []struct {
+		Points      []GeoPoint
+		Wrap        bool
+		TopLeft     GeoPoint
+		BottomRight GeoPoint
+	}{
+		{[]GeoPoint{{10, 170}, {-10, -170}}, true, GeoPoint{10, 170}, GeoPoint{-10, -170}},
+		{[]GeoPoint{{10, 170}, {-10, -170}}, false, GeoPoint{10, -170}, GeoPoint{-10, 170}},
+		{[]GeoPoint{{10, 10}, {-10, -10}}, true, GeoPoint{10, -10}, GeoPoint{-10, 10}},
+		{[]GeoPoint{{1, -5}, {2, -1}}, true, GeoPoint{2, -5}, GeoPoint{1, -1}},
+	}
+	for _, v := range tr {
+		gb := GeoBounds{WrapLongitude: v.Wrap}
+		for _, p := range v.Points {
+			gb.Add(p)
+		}
+		tl, br, ok := gb.Corners()
+		if !ok || tl != v.TopLeft || br != v.BottomRight {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v\n\n\texp: %v %v\n\n\tgot: %v %v\n\n", filepath.Base(file), line, v.Points, v.TopLeft, v.BottomRight, tl, br)
+			t.FailNow()
+		}
+	}
+
+	if got, _ := json.Marshal(GeoBounds{}); string(got) != `{}` {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %s\n\n", filepath.Base(file), line, got)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,344 @@
+package geohash
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// supported batch record formats
+const (
+	BatchCSV    = "csv"
+	BatchNDJSON = "ndjson"
+)
+
+// BatchOptions controls how Batch reads and enriches records
+type BatchOptions struct {
+	// Format of input and output, BatchCSV (default) or BatchNDJSON
+	Format string
+	// LatField and LngField name coordinate columns, "lat" and "lng" by default
+	LatField, LngField string
+	// Precisions of hashes appended as "<Column>_<precision>" in encode mode
+	Precisions []int
+	// Column is prefix of appended hash columns, "geohash" by default
+	Column string
+	// HashField switches to decode mode, hash column is decoded into
+	// "<HashField>_lat", "_lng", "_min_lat", "_min_lng", "_max_lat" and "_max_lng"
+	HashField string
+	// SkipInvalid skips bad rows instead of stopping at the first one
+	SkipInvalid bool
+	// Report is called for every skipped row
+	Report func(RowError)
+}
+
+// BatchStats counts processed records
+type BatchStats struct {
+	Rows    int
+	Skipped int
+}
+
+// RowError locates a bad record, Line is 1-based line number in input
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (re RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", re.Line, re.Err)
+}
+
+func (re RowError) Unwrap() error {
+	return re.Err
+}
+
+// Batch reads CSV or NDJSON records from r, appends hash columns
+// (or decoded columns of hash column) computed by cryptor and writes them to w.
+// Records are streamed one at a time so memory is bounded by the longest record.
+func Batch(c GeoCryptor, r io.Reader, w io.Writer, opt BatchOptions) (BatchStats, error) {
+	if opt.LatField == "" {
+		opt.LatField = "lat"
+	}
+	if opt.LngField == "" {
+		opt.LngField = "lng"
+	}
+	if opt.Column == "" {
+		opt.Column = "geohash"
+	}
+	if opt.HashField == "" && len(opt.Precisions) == 0 {
+		return BatchStats{}, errors.New("batch needs precisions or hash field")
+	}
+	for _, p := range opt.Precisions {
+		if p <= 0 {
+			return BatchStats{}, fmt.Errorf("invalid precision %d", p)
+		}
+		if err := CheckPrecision(c, p); err != nil {
+			return BatchStats{}, err
+		}
+	}
+
+	b := &batcher{c: c, opt: opt}
+	switch opt.Format {
+	case "", BatchCSV:
+		return b.csv(r, w)
+	case BatchNDJSON:
+		return b.ndjson(r, w)
+	}
+	return BatchStats{}, fmt.Errorf("unknown batch format %q", opt.Format)
+}
+
+type batcher struct {
+	c     GeoCryptor
+	opt   BatchOptions
+	stats BatchStats
+}
+
+// columns returns names of appended columns
+func (b *batcher) columns() []string {
+	if b.opt.HashField != "" {
+		n := []string{}
+		for _, s := range []string{"lat", "lng", "min_lat", "min_lng", "max_lat", "max_lng"} {
+			n = append(n, b.opt.HashField+"_"+s)
+		}
+		return n
+	}
+	n := []string{}
+	for _, p := range b.opt.Precisions {
+		n = append(n, b.opt.Column+"_"+strconv.Itoa(p))
+	}
+	return n
+}
+
+// enrich computes appended values, decoded values are float64 and hashes are string
+func (b *batcher) enrich(lat, lng, hash string, dst []interface{}) ([]interface{}, error) {
+	if b.opt.HashField != "" {
+		if err := validHash(b.c.HashKey(), hash); err != nil {
+			return dst, err
+		}
+		lb, err := decodeBox(b.c, hash)
+		if err != nil {
+			return dst, err
+		}
+		clat, clng := b.c.Decode(hash, len(hash))
+		return append(dst, clat, clng, lb.MinLat, lb.MinLng, lb.MaxLat, lb.MaxLng), nil
+	}
+	flat, err := parseCoord(b.opt.LatField, lat, MinLat, MaxLat)
+	if err != nil {
+		return dst, err
+	}
+	flng, err := parseCoord(b.opt.LngField, lng, MinLng, MaxLng)
+	if err != nil {
+		return dst, err
+	}
+	for _, p := range b.opt.Precisions {
+		dst = append(dst, b.c.Encode(flat, flng, p))
+	}
+	return dst, nil
+}
+
+// decodeBox returns box of hash, or the precision error of cryptor for too long hash
+func decodeBox(c GeoCryptor, hash string) (LocationBox, error) {
+	if d, ok := c.(interface {
+		DecodeBox(value string, precision int) (LocationBox, error)
+	}); ok {
+		return d.DecodeBox(hash, len(hash))
+	}
+	if err := CheckPrecision(c, len(hash)); err != nil {
+		return LocationBox{}, err
+	}
+	return *c.DecodeAsBox(hash, len(hash)).(*LocationBox), nil
+}
+
+// fail records bad row, returns non-nil error when batch should stop
+func (b *batcher) fail(line int, err error) error {
+	re := RowError{Line: line, Err: err}
+	if !b.opt.SkipInvalid {
+		return re
+	}
+	b.stats.Skipped++
+	if b.opt.Report != nil {
+		b.opt.Report(re)
+	}
+	return nil
+}
+
+func (b *batcher) csv(r io.Reader, w io.Writer) (BatchStats, error) {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	cr.FieldsPerRecord = -1
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	header, err := cr.Read()
+	if err != nil {
+		return b.stats, RowError{Line: 1, Err: err}
+	}
+	header = append([]string{}, header...)
+	latIdx, lngIdx, hashIdx := -1, -1, -1
+	for i, h := range header {
+		switch h {
+		case b.opt.LatField:
+			latIdx = i
+		case b.opt.LngField:
+			lngIdx = i
+		case b.opt.HashField:
+			hashIdx = i
+		}
+	}
+	if b.opt.HashField != "" && hashIdx < 0 {
+		return b.stats, RowError{Line: 1, Err: fmt.Errorf("missing column %q", b.opt.HashField)}
+	}
+	if b.opt.HashField == "" && (latIdx < 0 || lngIdx < 0) {
+		return b.stats, RowError{Line: 1, Err: fmt.Errorf("missing column %q or %q", b.opt.LatField, b.opt.LngField)}
+	}
+	if err := cw.Write(append(header, b.columns()...)); err != nil {
+		return b.stats, err
+	}
+
+	field := func(rec []string, i int) string {
+		if i < 0 || i >= len(rec) {
+			return ""
+		}
+		return rec[i]
+	}
+	values, out := []interface{}{}, []string{}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := cr.FieldPos(0)
+		if err != nil {
+			if pe, ok := err.(*csv.ParseError); ok {
+				line = pe.Line
+			}
+			if ferr := b.fail(line, err); ferr != nil {
+				return b.stats, ferr
+			}
+			continue
+		}
+		values, err = b.enrich(field(rec, latIdx), field(rec, lngIdx), field(rec, hashIdx), values[:0])
+		if err != nil {
+			if ferr := b.fail(line, err); ferr != nil {
+				return b.stats, ferr
+			}
+			continue
+		}
+		out = append(out[:0], rec...)
+		for _, v := range values {
+			out = append(out, formatValue(v))
+		}
+		if err := cw.Write(out); err != nil {
+			return b.stats, err
+		}
+		b.stats.Rows++
+	}
+	cw.Flush()
+	return b.stats, cw.Error()
+}
+
+func (b *batcher) ndjson(r io.Reader, w io.Writer) (BatchStats, error) {
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+	names := [][]byte{}
+	for _, n := range b.columns() {
+		q, _ := json.Marshal(n)
+		names = append(names, q)
+	}
+	values := []interface{}{}
+	for line := 1; ; line++ {
+		raw, rerr := br.ReadBytes('\n')
+		if rerr != nil && rerr != io.EOF {
+			return b.stats, rerr
+		}
+		raw = bytes.TrimSpace(raw)
+		if len(raw) > 0 {
+			if err := b.ndjsonRecord(bw, raw, names, values[:0]); err != nil {
+				if ferr := b.fail(line, err); ferr != nil {
+					return b.stats, ferr
+				}
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+	}
+	return b.stats, bw.Flush()
+}
+
+// ndjsonRecord appends new fields in front of closing brace of raw object
+// so the original fields are written back untouched
+func (b *batcher) ndjsonRecord(w *bufio.Writer, raw []byte, names [][]byte, values []interface{}) error {
+	rec := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return err
+	}
+	values, err := b.enrich(rawField(rec[b.opt.LatField]), rawField(rec[b.opt.LngField]), rawField(rec[b.opt.HashField]), values)
+	if err != nil {
+		return err
+	}
+
+	w.Write(raw[:len(raw)-1])
+	for i, v := range values {
+		if i > 0 || len(rec) > 0 {
+			w.WriteByte(',')
+		}
+		w.Write(names[i])
+		w.WriteByte(':')
+		if s, ok := v.(string); ok {
+			q, _ := json.Marshal(s)
+			w.Write(q)
+		} else {
+			w.WriteString(formatValue(v))
+		}
+	}
+	w.WriteString("}\n")
+	b.stats.Rows++
+	return nil
+}
+
+// rawField returns JSON string or number as text
+func rawField(m json.RawMessage) string {
+	s := strings.TrimSpace(string(m))
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+	return s
+}
+
+func parseCoord(name, s string, min, max float64) (float64, error) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", name, s)
+	}
+	if f < min || f > max {
+		return 0, fmt.Errorf("%s %v out of range [%v, %v]", name, f, min, max)
+	}
+	return f, nil
+}
+
+func formatValue(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// validHash checks hash is non-empty and consists of key characters
+func validHash(key, value string) error {
+	if value == "" {
+		return errors.New("empty hash")
+	}
+	for i := 0; i < len(value); i++ {
+		if strings.IndexByte(key, value[i]) < 0 {
+			return fmt.Errorf("invalid character %q at %d of %q", value[i], i, value)
+		}
+	}
+	return nil
+}
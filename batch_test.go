@@ -0,0 +1,76 @@
+package geohash
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestBatchCSV(t *testing.T) {
+	cryptor := NewDefaultGeoHash()
+	out := &bytes.Buffer{}
+
+	in := "name,y,x\na,-2,-3\nb,12.04512315,118.20385763\n"
+	stats, err := Batch(cryptor, strings.NewReader(in), out, BatchOptions{LatField: "y", LngField: "x", Precisions: []int{1, 6}})
+	exp := "name,y,x,geohash_1,geohash_6\na,-2,-3,7,7ztuee\nb,12.04512315,118.20385763,w,wdhh9b\n"
+	if err != nil || out.String() != exp || stats.Rows != 2 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, exp, out.String(), err)
+		t.FailNow()
+	}
+
+	out.Reset()
+	in = "id,h\n1,7\n2,7ztuea\n"
+	_, err = Batch(cryptor, strings.NewReader(in), out, BatchOptions{HashField: "h"})
+	if re, ok := err.(RowError); !ok || re.Line != 3 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: error at line 3\n\n\tgot: %v\n\n", filepath.Base(file), line, err)
+		t.FailNow()
+	}
+	expOut := "id,h,h_lat,h_lng,h_min_lat,h_min_lng,h_max_lat,h_max_lng\n1,7,-22.5,-22.5,-45,-45,0,0\n"
+	if out.String() != expOut {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, expOut, out.String())
+		t.FailNow()
+	}
+	// hashes too long for cryptor are bad rows
+	out.Reset()
+	in = "id,h\n1,wdhh9b9rvzzzzzzzzzzz\n"
+	_, err = Batch(cryptor, strings.NewReader(in), out, BatchOptions{HashField: "h"})
+	if re, ok := err.(RowError); !ok || re.Line != 2 || !errors.As(err, &PrecisionError{}) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: precision error at line 2\n\n\tgot: %v\n\n", filepath.Base(file), line, err)
+		t.FailNow()
+	}
+}
+
+func TestBatchNDJSON(t *testing.T) {
+	cryptor := NewDefaultGeoHash()
+	out := &bytes.Buffer{}
+	reported := []int{}
+
+	in := "{\"id\":1,\"lat\":-2,\"lng\":\"-3\"}\n\n{\"lat\":100,\"lng\":0}\nnot json\n{\"lat\":-2,\"lng\":-3}"
+	stats, err := Batch(cryptor, strings.NewReader(in), out, BatchOptions{
+		Format: BatchNDJSON, Precisions: []int{6}, SkipInvalid: true,
+		Report: func(re RowError) { reported = append(reported, re.Line) }})
+	exp := "{\"id\":1,\"lat\":-2,\"lng\":\"-3\",\"geohash_6\":\"7ztuee\"}\n{\"lat\":-2,\"lng\":-3,\"geohash_6\":\"7ztuee\"}\n"
+	if err != nil || out.String() != exp || stats.Rows != 2 || stats.Skipped != 2 || !reflect.DeepEqual(reported, []int{3, 4}) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v, %v, %v, %v\n\n", filepath.Base(file), line, exp, out.String(), stats, reported, err)
+		t.FailNow()
+	}
+}
+
+func BenchmarkBatchCSV(b *testing.B) {
+	cryptor := NewDefaultGeoHash()
+	in := strings.Repeat("12.04512315,118.20385763\n", 1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Batch(cryptor, strings.NewReader("lat,lng\n"+in), &bytes.Buffer{}, BatchOptions{Precisions: []int{5, 9}})
+	}
+}
@@ -0,0 +1,130 @@
+package geohash
+
+import "math"
+
+// Normalize returns box with longitudes wrapped into [-180, 180],
+// a box as wide as the globe or wider spans every longitude
+func (lb LocationBox) Normalize() LocationBox {
+	if lb.MinLng <= lb.MaxLng && lb.MaxLng-lb.MinLng >= 2*MaxLng {
+		lb.MinLng, lb.MaxLng = MinLng, MaxLng
+		return lb
+	}
+	minLng, maxLng := wrapLng(lb.MinLng, false), wrapLng(lb.MaxLng, true)
+	if minLng == MaxLng && maxLng != MaxLng {
+		minLng = MinLng
+	}
+	if maxLng == MinLng && minLng != MinLng {
+		maxLng = MaxLng
+	}
+	lb.MinLng, lb.MaxLng = minLng, maxLng
+	return lb
+}
+
+// wrapLng moves longitude out of [-180, 180] into [-180, 180), or (-180, 180] for upper edges
+func wrapLng(lng float64, upper bool) float64 {
+	if lng >= MinLng && lng <= MaxLng {
+		return lng
+	}
+	if upper {
+		return lng + 2*MaxLng*math.Floor((MaxLng-lng)/(2*MaxLng))
+	}
+	return lng - 2*MaxLng*math.Floor((lng-MinLng)/(2*MaxLng))
+}
+
+// CrossesAntimeridian reports whether box spans the 180th meridian
+func (lb LocationBox) CrossesAntimeridian() bool {
+	return lb.MinLng > lb.MaxLng
+}
+
+// split returns parts of box west and east of the antimeridian,
+// a box not crossing it is its only part
+func (lb LocationBox) split() []LocationBox {
+	if !lb.CrossesAntimeridian() {
+		return []LocationBox{lb}
+	}
+	west, east := lb, lb
+	west.MaxLng, east.MinLng = MaxLng, MinLng
+	return []LocationBox{west, east}
+}
+
+// Width returns longitudinal extent of box in degrees
+func (lb LocationBox) Width() float64 {
+	if lb.CrossesAntimeridian() {
+		return lb.MaxLng - lb.MinLng + 2*MaxLng
+	}
+	return lb.MaxLng - lb.MinLng
+}
+
+// Area returns surface of box in square meters on the sphere of EarthRadius
+func (lb LocationBox) Area() float64 {
+	rad := math.Pi / 180
+	return EarthRadius * EarthRadius * lb.Width() * rad *
+		math.Abs(math.Sin(lb.MaxLat*rad)-math.Sin(lb.MinLat*rad))
+}
+
+// Contains reports whether point lies inside box or on its edges
+func (lb LocationBox) Contains(lat, lng float64) bool {
+	if lat < lb.MinLat || lat > lb.MaxLat {
+		return false
+	}
+	in := func(lng float64) bool {
+		if lb.CrossesAntimeridian() {
+			return lng >= lb.MinLng || lng <= lb.MaxLng
+		}
+		return lng >= lb.MinLng && lng <= lb.MaxLng
+	}
+	lng = wrapLng(lng, false)
+	// -180 and 180 are the same meridian
+	return in(lng) || lng == MinLng && in(MaxLng) || lng == MaxLng && in(MinLng)
+}
+
+// Intersects reports whether box shares any point with o, touching edges included
+func (lb LocationBox) Intersects(o LocationBox) bool {
+	return len(lb.Intersection(o)) > 0
+}
+
+// Intersection returns area shared by box and o, nil when they are disjoint.
+// Boxes overlapping on both sides of the antimeridian share two separate boxes
+func (lb LocationBox) Intersection(o LocationBox) []LocationBox {
+	minLat, maxLat := math.Max(lb.MinLat, o.MinLat), math.Min(lb.MaxLat, o.MaxLat)
+	if minLat > maxLat {
+		return nil
+	}
+	a, b := lb.Normalize(), o.Normalize()
+	if a.Width() >= 2*MaxLng {
+		a, b = b, a
+	}
+	if b.Width() >= 2*MaxLng {
+		return []LocationBox{newBox(minLat, a.MinLng, maxLat, a.MinLng+a.Width())}
+	}
+	var n []LocationBox
+	for _, shift := range []float64{-2 * MaxLng, 0, 2 * MaxLng} {
+		minLng := math.Max(a.MinLng, b.MinLng+shift)
+		maxLng := math.Min(a.MinLng+a.Width(), b.MinLng+b.Width()+shift)
+		if minLng <= maxLng {
+			n = append(n, newBox(minLat, minLng, maxLat, maxLng))
+		}
+	}
+	return n
+}
+
+// Union returns the smallest box which contains both box and o
+func (lb LocationBox) Union(o LocationBox) LocationBox {
+	a, b := lb.Normalize(), o.Normalize()
+	// the smallest enclosing span starts at the west edge of either box
+	span := func(a, b LocationBox) float64 {
+		return math.Max(a.Width(), math.Mod(b.MinLng-a.MinLng+2*MaxLng, 2*MaxLng)+b.Width())
+	}
+	minLng, width := a.MinLng, span(a, b)
+	if w := span(b, a); w < width {
+		minLng, width = b.MinLng, w
+	}
+	return newBox(math.Min(a.MinLat, b.MinLat), minLng, math.Max(a.MaxLat, b.MaxLat), minLng+width)
+}
+
+// newBox returns normalized box without hash
+func newBox(minLat, minLng, maxLat, maxLng float64) LocationBox {
+	lb := LocationBox{MaxLat: maxLat, MinLat: minLat, MaxLng: maxLng, MinLng: minLng}.Normalize()
+	lb.LatErr, lb.LngErr = (lb.MaxLat-lb.MinLat)/2, lb.Width()/2
+	return lb
+}
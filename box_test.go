@@ -0,0 +1,181 @@
+package geohash
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func lbox(minLat, minLng, maxLat, maxLng float64) LocationBox {
+	return LocationBox{MaxLat: maxLat, MinLat: minLat, MaxLng: maxLng, MinLng: minLng}
+}
+
+func corners(boxes ...LocationBox) [][4]float64 {
+	n := [][4]float64{}
+	for _, b := range boxes {
+		n = append(n, [4]float64{b.MinLat, b.MinLng, b.MaxLat, b.MaxLng})
+	}
+	return n
+}
+
+func TestNormalize(t *testing.T) {
+	tr := []struct {
+		In, Exp LocationBox
+	}{
+		{lbox(0, 170, 10, 190), lbox(0, 170, 10, -170)},
+		{lbox(0, -190, 10, -170), lbox(0, 170, 10, -170)},
+		{lbox(0, 180, 10, 190), lbox(0, -180, 10, -170)},
+		{lbox(0, 170, 10, 180), lbox(0, 170, 10, 180)},
+		{lbox(0, 10, 10, 370), lbox(0, -180, 10, 180)},
+		{lbox(0, 530, 10, 540), lbox(0, 170, 10, 180)},
+		{lbox(0, 170, 10, -170), lbox(0, 170, 10, -170)},
+	}
+	for _, v := range tr {
+		if got := v.In.Normalize(); !reflect.DeepEqual(corners(got), corners(v.Exp)) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v\n\n\texp: %v\n\n\tgot: %v\n\n", filepath.Base(file), line, corners(v.In), corners(v.Exp), corners(got))
+			t.FailNow()
+		}
+	}
+}
+
+func TestBoxContains(t *testing.T) {
+	pacific := lbox(-10, 170, 10, -170)
+	tr := []struct {
+		Box      LocationBox
+		Lat, Lng float64
+		Exp      bool
+	}{
+		{pacific, 0, 180, true},
+		{pacific, 0, -180, true},
+		{pacific, 0, 175, true},
+		{pacific, 0, -175, true},
+		{pacific, 0, 185, true},
+		{pacific, 10, -170, true},
+		{pacific, 0, 0, false},
+		{pacific, 0, 165, false},
+		{pacific, 11, 180, false},
+		{lbox(0, 170, 10, 180), 5, -180, true},
+		{lbox(0, -180, 10, -170), 5, 180, true},
+		{lbox(0, -10, 10, 10), 5, 0, true},
+		{lbox(0, -10, 10, 10), 5, 180, false},
+	}
+	for _, v := range tr {
+		if got := v.Box.Contains(v.Lat, v.Lng); got != v.Exp {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v (%v, %v)\n\n\texp: %v\n\n\tgot: %v\n\n", filepath.Base(file), line, corners(v.Box), v.Lat, v.Lng, v.Exp, got)
+			t.FailNow()
+		}
+	}
+}
+
+func TestBoxIntersection(t *testing.T) {
+	pacific := lbox(-10, 170, 10, -170)
+	tr := []struct {
+		A, B LocationBox
+		Exp  [][4]float64
+	}{
+		{pacific, lbox(0, 175, 20, 180), [][4]float64{{0, 175, 10, 180}}},
+		{pacific, lbox(0, -180, 20, -175), [][4]float64{{0, -180, 10, -175}}},
+		{pacific, lbox(-20, 160, 20, -160), [][4]float64{{-10, 170, 10, -170}}},
+		{pacific, lbox(-5, -175, 5, 175), [][4]float64{{-5, 170, 5, 175}, {-5, -175, 5, -170}}},
+		{pacific, lbox(0, -10, 10, 10), nil},
+		{pacific, lbox(20, 175, 30, -175), nil},
+		{pacific, lbox(10, -170, 20, 0), [][4]float64{{10, -170, 10, -170}}},
+		{lbox(0, -180, 10, 180), pacific, [][4]float64{{0, 170, 10, -170}}},
+		{lbox(0, 0, 10, 20), lbox(5, 10, 15, 30), [][4]float64{{5, 10, 10, 20}}},
+	}
+	for _, v := range tr {
+		got := corners(v.A.Intersection(v.B)...)
+		if len(v.Exp) == 0 && len(got) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, v.Exp) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v, %v\n\n\texp: %v\n\n\tgot: %v\n\n", filepath.Base(file), line, corners(v.A), corners(v.B), v.Exp, got)
+			t.FailNow()
+		}
+		if v.A.Intersects(v.B) != (len(v.Exp) > 0) || v.B.Intersects(v.A) != (len(v.Exp) > 0) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v, %v\n\n\texp intersects: %v\n\n", filepath.Base(file), line, corners(v.A), corners(v.B), len(v.Exp) > 0)
+			t.FailNow()
+		}
+	}
+}
+
+func TestBoxUnion(t *testing.T) {
+	tr := []struct {
+		A, B LocationBox
+		Exp  LocationBox
+	}{
+		{lbox(0, 170, 10, 175), lbox(-10, -175, 0, -170), lbox(-10, 170, 10, -170)},
+		{lbox(0, -175, 10, -170), lbox(-10, 170, 0, 175), lbox(-10, 170, 10, -170)},
+		{lbox(0, -10, 10, 0), lbox(0, 0, 10, 10), lbox(0, -10, 10, 10)},
+		{lbox(0, 0, 10, 10), lbox(0, -5, 10, 5), lbox(0, -5, 10, 10)},
+		{lbox(0, 170, 10, -170), lbox(0, 0, 10, 1), lbox(0, 0, 10, -170)},
+		{lbox(0, 170, 10, -170), lbox(0, -10, 10, 175), lbox(0, -10, 10, -170)},
+		{lbox(0, 90, 10, -90), lbox(0, -100, 10, 100), lbox(0, -180, 10, 180)},
+	}
+	for _, v := range tr {
+		if got := v.A.Union(v.B); !reflect.DeepEqual(corners(got), corners(v.Exp)) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v, %v\n\n\texp: %v\n\n\tgot: %v\n\n", filepath.Base(file), line, corners(v.A), corners(v.B), corners(v.Exp), corners(got))
+			t.FailNow()
+		}
+	}
+}
+
+func TestBoxWidthArea(t *testing.T) {
+	pacific := lbox(-10, 170, 10, -170)
+	if w := pacific.Width(); w != 20 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %v\n\n\tgot: %v\n\n", filepath.Base(file), line, 20, w)
+		t.FailNow()
+	}
+	if a, exp := pacific.Area(), lbox(-10, -10, 10, 10).Area(); a != exp {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %v\n\n\tgot: %v\n\n", filepath.Base(file), line, exp, a)
+		t.FailNow()
+	}
+	world := 4 * math.Pi * EarthRadius * EarthRadius
+	if a := lbox(MinLat, MinLng, MaxLat, MaxLng).Area(); math.Abs(a-world) > world*1e-12 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %v\n\n\tgot: %v\n\n", filepath.Base(file), line, world, a)
+		t.FailNow()
+	}
+	if lat, lng := pacific.Center(); lat != 0 || lng != 180 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: (0, 180)\n\n\tgot: (%v, %v)\n\n", filepath.Base(file), line, lat, lng)
+		t.FailNow()
+	}
+}
+
+func TestAntimeridianNeighborsCover(t *testing.T) {
+	cryptor := NewDefaultGeoHash()
+	got := []string{}
+	for _, v := range cryptor.Neighbors("8", 1)[3:5] {
+		h, _ := v.Geohash()
+		got = append(got, h)
+	}
+	exp := []string{"x", "9"}
+	if !reflect.DeepEqual(exp, got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+
+	got = []string{}
+	for _, v := range CoverBox(cryptor, 0, 170, 10, -170, 1) {
+		h, _ := v.Geohash()
+		got = append(got, h)
+	}
+	exp = []string{"x", "8"}
+	if !reflect.DeepEqual(exp, got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,376 @@
+/* Command geohash encodes and decodes geohash and geohash-36 values
+
+Usage:
+
+    geohash <command> [flags] args...
+
+Commands:
+
+    encode LAT LNG                          hash of point
+    decode HASH                             center point and error of hash
+    box HASH                                bounding box of hash
+    neighbors HASH                          8 adjacent cells
+    parent HASH                             enclosing cell one level up
+    children HASH                           cells one level down
+    cover MINLAT MINLNG MAXLAT MAXLNG       cells covering box
+    batch                                   enrich CSV or NDJSON records from stdin
+
+Flags, every flag takes a value and may appear anywhere after command:
+
+    -scheme geohash|geohash36   hash scheme, default geohash
+    -key KEY                    custom alphabet, default key of scheme
+    -precision N                hash length for encode and cover, default 9
+    -format text|json|geojson   output format, default text
+    -exact true|false           exact arithmetic for geohash longer than 16, default false
+
+Batch flags:
+
+    -input csv|ndjson           record format, default csv
+    -lat NAME -lng NAME         coordinate columns, default lat and lng
+    -precisions N,N...          hash columns to append, default -precision
+    -hash NAME                  decode hash column instead of encoding
+    -on-error fail|skip|report  stop at bad row, skip it or skip and report to stderr
+
+Example:
+
+    $ geohash encode -precision 6 -2 -3
+    7ztuee
+    $ geohash decode 7ztuee
+    -2.002258 -3.004761
+    $ geohash batch -precisions 5,7 < points.csv > enriched.csv
+*/
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/myyang/geohash"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+type options struct {
+	cryptor   geohash.GeoCryptor
+	precision int
+	format    string
+	batch     geohash.BatchOptions
+	stdin     io.Reader
+	stderr    io.Writer
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "usage: geohash <encode|decode|box|neighbors|parent|children|cover|batch> [flags] args...")
+		return 2
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+		return 2
+	}
+
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	scheme := fs.String("scheme", "geohash", "hash scheme, geohash or geohash36")
+	key := fs.String("key", "", "custom alphabet, default key of scheme")
+	precision := fs.Int("precision", 9, "hash length for encode and cover")
+	format := fs.String("format", "text", "output format, text, json or geojson")
+	exact := fs.String("exact", "false", "exact arithmetic for long geohash values, true or false")
+	input := fs.String("input", geohash.BatchCSV, "batch record format, csv or ndjson")
+	lat := fs.String("lat", "lat", "batch latitude column")
+	lng := fs.String("lng", "lng", "batch longitude column")
+	precisions := fs.String("precisions", "", "batch hash precisions separated by comma, default -precision")
+	hash := fs.String("hash", "", "batch hash column to decode")
+	onError := fs.String("on-error", "fail", "batch bad row handling, fail, skip or report")
+
+	flags, pos := splitArgs(args[1:])
+	if err := fs.Parse(flags); err != nil {
+		return 2
+	}
+	s, err := geohash.ParseScheme(*scheme)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	if *key == "" {
+		*key = s.Key()
+	}
+	if *format != "text" && *format != "json" && *format != "geojson" {
+		fmt.Fprintf(stderr, "unknown format %q\n", *format)
+		return 2
+	}
+	opt := options{cryptor: s.NewCryptorWithKey(*key), precision: *precision, format: *format, stdin: stdin, stderr: stderr}
+	if big, err := strconv.ParseBool(*exact); err != nil || (big && s != geohash.SchemeGeoHash) {
+		fmt.Fprintf(stderr, "invalid exact %q, only geohash supports exact arithmetic\n", *exact)
+		return 2
+	} else if big {
+		opt.cryptor = geohash.NewGeoHashBig(*key)
+	}
+	opt.batch = geohash.BatchOptions{Format: *input, LatField: *lat, LngField: *lng, HashField: *hash}
+	if opt.batch.Precisions, err = ints(*precisions, *precision); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	switch *onError {
+	case "fail":
+	case "skip", "report":
+		opt.batch.SkipInvalid = true
+	default:
+		fmt.Fprintf(stderr, "unknown on-error %q\n", *onError)
+		return 2
+	}
+	if *onError == "report" {
+		opt.batch.Report = func(re geohash.RowError) { fmt.Fprintln(stderr, re) }
+	}
+
+	if err := cmd(opt, pos, stdout); err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// splitArgs separates flags and positional arguments, every flag takes a value
+// and negative numbers are positional
+func splitArgs(args []string) (flags, pos []string) {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if _, err := strconv.ParseFloat(a, 64); err == nil || !strings.HasPrefix(a, "-") || a == "-" {
+			pos = append(pos, a)
+			continue
+		}
+		flags = append(flags, a)
+		if !strings.Contains(a, "=") && i+1 < len(args) {
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+	return flags, pos
+}
+
+var commands = map[string]func(opt options, args []string, w io.Writer) error{
+	"encode":    encode,
+	"decode":    decode,
+	"box":       box,
+	"neighbors": neighbors,
+	"parent":    parent,
+	"children":  children,
+	"cover":     cover,
+	"batch":     batch,
+}
+
+func encode(opt options, args []string, w io.Writer) error {
+	f, err := floats(args, 2)
+	if err != nil {
+		return err
+	}
+	if err := checkPrecision(opt); err != nil {
+		return err
+	}
+	h, latErr, lngErr := opt.cryptor.EncodeWithErr(f[0], f[1], opt.precision)
+	switch opt.format {
+	case "json":
+		return writeJSON(w, map[string]interface{}{"hash": h, "latErr": latErr, "lngErr": lngErr})
+	case "geojson":
+		return writeBox(opt, opt.cryptor.EncodeAsBox(f[0], f[1], opt.precision), w)
+	}
+	_, err = fmt.Fprintln(w, h)
+	return err
+}
+
+func decode(opt options, args []string, w io.Writer) error {
+	h, err := hashArg(opt, args)
+	if err != nil {
+		return err
+	}
+	lat, lng, latErr, lngErr := opt.cryptor.DecodeWithErr(h, len(h))
+	switch opt.format {
+	case "json":
+		return writeJSON(w, map[string]interface{}{"lat": lat, "lng": lng, "latErr": latErr, "lngErr": lngErr})
+	case "geojson":
+		return writeBox(opt, opt.cryptor.DecodeAsBox(h, len(h)), w)
+	}
+	_, err = fmt.Fprintln(w, formatFloat(lat), formatFloat(lng))
+	return err
+}
+
+func box(opt options, args []string, w io.Writer) error {
+	h, err := hashArg(opt, args)
+	if err != nil {
+		return err
+	}
+	return writeBox(opt, opt.cryptor.DecodeAsBox(h, len(h)), w)
+}
+
+func neighbors(opt options, args []string, w io.Writer) error {
+	h, err := hashArg(opt, args)
+	if err != nil {
+		return err
+	}
+	return writeBoxes(opt, opt.cryptor.Neighbors(h, len(h)), w)
+}
+
+func parent(opt options, args []string, w io.Writer) error {
+	h, err := hashArg(opt, args)
+	if err != nil {
+		return err
+	}
+	if len(h) < 2 {
+		return errors.New("top level cell has no parent")
+	}
+	p := geohash.Parent(h)
+	if opt.format == "text" {
+		_, err = fmt.Fprintln(w, p)
+		return err
+	}
+	return writeBox(opt, opt.cryptor.DecodeAsBox(p, len(p)), w)
+}
+
+func children(opt options, args []string, w io.Writer) error {
+	h, err := hashArg(opt, args)
+	if err != nil {
+		return err
+	}
+	hashes := geohash.Children(opt.cryptor, h)
+	boxes := make([]geohash.BoundingBox, 0, len(hashes))
+	for _, c := range hashes {
+		boxes = append(boxes, opt.cryptor.DecodeAsBox(c, len(c)))
+	}
+	return writeBoxes(opt, boxes, w)
+}
+
+func cover(opt options, args []string, w io.Writer) error {
+	f, err := floats(args, 4)
+	if err != nil {
+		return err
+	}
+	if err := checkPrecision(opt); err != nil {
+		return err
+	}
+	return writeBoxes(opt, geohash.CoverBox(opt.cryptor, f[0], f[1], f[2], f[3], opt.precision), w)
+}
+
+func batch(opt options, args []string, w io.Writer) error {
+	if len(args) != 0 {
+		return fmt.Errorf("batch reads stdin, got %d arguments", len(args))
+	}
+	stats, err := geohash.Batch(opt.cryptor, opt.stdin, w, opt.batch)
+	if stats.Skipped > 0 {
+		fmt.Fprintf(opt.stderr, "batch: %d rows written, %d skipped\n", stats.Rows, stats.Skipped)
+	}
+	return err
+}
+
+func hashArg(opt options, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("expect 1 hash argument, got %d", len(args))
+	}
+	if args[0] == "" {
+		return "", errors.New("empty hash")
+	}
+	key := opt.cryptor.HashKey()
+	for i := 0; i < len(args[0]); i++ {
+		if strings.IndexByte(key, args[0][i]) < 0 {
+			return "", fmt.Errorf("invalid character %q at %d of %q", args[0][i], i, args[0])
+		}
+	}
+	if err := geohash.CheckPrecision(opt.cryptor, len(args[0])); err != nil {
+		return "", err
+	}
+	return args[0], nil
+}
+
+func checkPrecision(opt options) error {
+	if opt.precision <= 0 {
+		return errors.New("precision must be positive")
+	}
+	return geohash.CheckPrecision(opt.cryptor, opt.precision)
+}
+
+func floats(args []string, n int) ([]float64, error) {
+	if len(args) != n {
+		return nil, fmt.Errorf("expect %d coordinate arguments, got %d", n, len(args))
+	}
+	f := make([]float64, 0, n)
+	for _, a := range args {
+		v, err := strconv.ParseFloat(a, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coordinate %q", a)
+		}
+		f = append(f, v)
+	}
+	return f, nil
+}
+
+// ints parses comma separated integers, def is used for empty string
+func ints(s string, def int) ([]int, error) {
+	if s == "" {
+		return []int{def}, nil
+	}
+	n := []int{}
+	for _, v := range strings.Split(s, ",") {
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("invalid precision %q", v)
+		}
+		n = append(n, i)
+	}
+	return n, nil
+}
+
+func writeBox(opt options, b geohash.BoundingBox, w io.Writer) error {
+	lb := b.(*geohash.LocationBox)
+	switch opt.format {
+	case "json":
+		return writeJSON(w, lb)
+	case "geojson":
+		return writeJSON(w, lb.Feature())
+	}
+	_, err := fmt.Fprintln(w, lb.Hash, formatFloat(lb.MinLat), formatFloat(lb.MinLng), formatFloat(lb.MaxLat), formatFloat(lb.MaxLng))
+	return err
+}
+
+func writeBoxes(opt options, boxes []geohash.BoundingBox, w io.Writer) error {
+	switch opt.format {
+	case "json":
+		lbs := []*geohash.LocationBox{}
+		for _, b := range boxes {
+			if lb, ok := b.(*geohash.LocationBox); ok && lb != nil {
+				lbs = append(lbs, lb)
+			}
+		}
+		return writeJSON(w, lbs)
+	case "geojson":
+		return writeJSON(w, geohash.NewFeatureCollection(boxes))
+	}
+	for _, b := range boxes {
+		if lb, ok := b.(*geohash.LocationBox); ok && lb != nil {
+			if _, err := fmt.Fprintln(w, lb.Hash); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", b)
+	return err
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/myyang/geohash"
+)
+
+func TestRun(t *testing.T) {
+	tr := []struct {
+		Args string
+		Code int
+		Out  string
+	}{
+		{"encode -precision 6 -2 -3", 0, "7ztuee\n"},
+		{"encode -2 -3 --precision=1", 0, "7\n"},
+		{"encode -scheme geohash36 -precision 10 51.504444 -0.086666", 0, "bdrdC26BqH\n"},
+		{"encode -format json -precision 1 -2 -3", 0, "{\"hash\":\"7\",\"latErr\":22.5,\"lngErr\":22.5}\n"},
+		{"decode 7ztuee", 0, "-2.002258 -3.004761\n"},
+		{"box 7", 0, "7 -45 -45 0 0\n"},
+		{"parent 7ztuee", 0, "7ztue\n"},
+		{"neighbors 7ztuee", 0, "7ztue6\n7ztued\n7ztuef\n7ztue7\n7ztueg\n7ztuek\n7ztues\n7ztueu\n"},
+		{"children -key 0123456789bcdefghjkmnpqrstuvwxyz 7ztue", 0, "7ztue0\n7ztue1\n"},
+		{"cover -precision 1 -1 -1 1 1", 0, "7\nk\ne\ns\n"},
+		{"cover -format geojson -precision 1 -1 -1 -1 -1", 0, "{\"type\":\"FeatureCollection\",\"features\":[{\"type\":\"Feature\""},
+		{"decode wdhha", 1, ""},
+		{"decode wdhh9b9rvzzzzzzzzzzz", 1, ""},
+		{"box wdhh9b9rvzzzzzzzzzzz", 1, ""},
+		{"neighbors wdhh9b9rvzzzzzzzzzzz", 1, ""},
+		{"decode -exact true wdhh9b9rvzzzzzzzzzzz", 0, "12.04"},
+		{"parent 7", 1, ""},
+		{"encode 1", 1, ""},
+		{"encode -precision 20 -2 -3", 1, ""},
+		{"encode -exact true -precision 20 -2 -3", 0, "7ztuee"},
+		{"encode -exact true -scheme geohash36 1 1", 2, ""},
+		// neighbors past the poles are skipped by every cryptor
+		{"neighbors -exact true z", 0, "w\nx\n8\ny\nb\n"},
+		{"neighbors -exact true -format json z", 0, "[{"},
+		{"encode -scheme geohash37 1 1", 2, ""},
+		{"encode -format xml 1 1", 2, ""},
+		{"unknown", 2, ""},
+	}
+
+	for _, v := range tr {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		code := run(strings.Fields(v.Args), strings.NewReader(""), stdout, stderr)
+		if code != v.Code || !strings.HasPrefix(stdout.String(), v.Out) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %s\n\n\texp: %v %#v\n\n\tgot: %v %#v %s\n\n",
+				filepath.Base(file), line, v.Args, v.Code, v.Out, code, stdout.String(), stderr.String())
+			t.FailNow()
+		}
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	in := "id,lat,lng\n1,-2,-3\n2,x,1\n3,12.04512315,118.20385763\n"
+	code := run([]string{"batch", "-precisions", "1,6", "-on-error", "report"}, strings.NewReader(in), stdout, stderr)
+	exp := "id,lat,lng,geohash_1,geohash_6\n1,-2,-3,7,7ztuee\n3,12.04512315,118.20385763,w,wdhh9b\n"
+	if code != 0 || stdout.String() != exp || !strings.Contains(stderr.String(), "line 3") {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %v %#v %s\n\n", filepath.Base(file), line, exp, code, stdout.String(), stderr.String())
+		t.FailNow()
+	}
+}
+
+func TestRunCustomKey(t *testing.T) {
+	reverse := func(s string) string {
+		b := []byte(s)
+		for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+			b[i], b[j] = b[j], b[i]
+		}
+		return string(b)
+	}
+	for _, s := range []geohash.Scheme{geohash.SchemeGeoHash, geohash.SchemeGeoHash36} {
+		key := reverse(s.Key())
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		if code := run([]string{"encode", "-scheme", s.String(), "-key", key, "-precision", "8", "51.504444", "-0.086666"},
+			strings.NewReader(""), stdout, stderr); code != 0 {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v\n\n\tgot: %v %s\n\n", filepath.Base(file), line, s, code, stderr.String())
+			t.FailNow()
+		}
+		hash := strings.TrimSpace(stdout.String())
+		stdout.Reset()
+		code := run([]string{"decode", "-scheme", s.String(), "-key", key, hash}, strings.NewReader(""), stdout, stderr)
+		var lat, lng float64
+		fmt.Sscan(stdout.String(), &lat, &lng)
+		if code != 0 || hash == s.NewCryptor().Encode(51.504444, -0.086666, 8) ||
+			math.Abs(lat-51.504444) > 0.001 || math.Abs(lng+0.086666) > 0.001 {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v %s\n\n\texp: 51.504444 -0.086666\n\n\tgot: %v %s %s\n\n",
+				filepath.Base(file), line, s, hash, code, stdout.String(), stderr.String())
+			t.FailNow()
+		}
+	}
+}
@@ -0,0 +1,441 @@
+// Command geohashd serves geohash operations over HTTP/JSON
+//
+// Usage:
+//
+//	geohashd -addr :8080
+//
+// Endpoints, every request and response body is JSON:
+//
+//	GET  /healthz        liveness
+//	GET  /readyz         readiness
+//	POST /v1/encode      {"scheme", "lat", "lng", "precision"}
+//	POST /v1/decode      {"scheme", "hash"}
+//	POST /v1/neighbors   {"scheme", "hash"}
+//	POST /v1/cover       {"scheme", "precision", "box"|"circle"|"polygon"}
+//	POST /v1/batch       {"requests": [{"op": "encode"|"decode"|"neighbors"|"cover", ...}]}
+//
+// Scheme is "geohash" (default) or "geohash36", failed requests answer
+// {"error": "..."} with status 4xx.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/myyang/geohash"
+)
+
+func main() {
+	cfg := defaultConfig
+	addr := flag.String("addr", ":8080", "listen address")
+	flag.Int64Var(&cfg.MaxBody, "max-body", cfg.MaxBody, "max request body in bytes")
+	flag.IntVar(&cfg.MaxBatch, "max-batch", cfg.MaxBatch, "max requests in a batch")
+	flag.IntVar(&cfg.MaxCells, "max-cells", cfg.MaxCells, "max cells returned by cover")
+	flag.IntVar(&cfg.MaxPrecision, "max-precision", cfg.MaxPrecision, "max hash precision")
+	flag.Parse()
+
+	srv := &http.Server{
+		Addr:         *addr,
+		Handler:      newServer(cfg),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	log.Printf("geohashd listening on %s", *addr)
+	log.Fatal(srv.ListenAndServe())
+}
+
+type config struct {
+	MaxBody      int64
+	MaxBatch     int
+	MaxCells     int
+	MaxPrecision int
+}
+
+var defaultConfig = config{MaxBody: 1 << 20, MaxBatch: 1000, MaxCells: 10000, MaxPrecision: 12}
+
+type server struct {
+	cfg config
+	mux *http.ServeMux
+}
+
+func newServer(cfg config) http.Handler {
+	s := &server{cfg: cfg, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/healthz", s.health)
+	s.mux.HandleFunc("/readyz", s.health)
+	s.mux.HandleFunc("/v1/encode", s.handle(func() request { return &encodeRequest{} }))
+	s.mux.HandleFunc("/v1/decode", s.handle(func() request { return &decodeRequest{} }))
+	s.mux.HandleFunc("/v1/neighbors", s.handle(func() request { return &neighborsRequest{} }))
+	s.mux.HandleFunc("/v1/cover", s.handle(func() request { return &coverRequest{} }))
+	s.mux.HandleFunc("/v1/batch", s.handle(func() request { return &batchRequest{} }))
+	return s.mux
+}
+
+func (s *server) health(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// request is a validated operation
+type request interface {
+	serve(s *server) (interface{}, error)
+}
+
+func (s *server) handle(newRequest func() request) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		req := newRequest()
+		dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.cfg.MaxBody))
+		dec.DisallowUnknownFields()
+		err := dec.Decode(req)
+		if err == nil {
+			err = expectEOF(dec)
+		}
+		if err != nil {
+			var mbe *http.MaxBytesError
+			if errors.As(err, &mbe) {
+				writeError(w, http.StatusRequestEntityTooLarge, err)
+				return
+			}
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		resp, err := req.serve(s)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// expectEOF rejects anything but whitespace after the decoded request
+func expectEOF(dec *json.Decoder) error {
+	if _, err := dec.Token(); err != io.EOF {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			return err
+		}
+		return errors.New("unexpected data after request")
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+func cryptor(scheme string) (geohash.GeoCryptor, error) {
+	s, err := parseScheme(scheme)
+	if err != nil {
+		return nil, err
+	}
+	return s.NewCryptor(), nil
+}
+
+func parseScheme(scheme string) (geohash.Scheme, error) {
+	if scheme == "" {
+		return geohash.SchemeGeoHash, nil
+	}
+	return geohash.ParseScheme(scheme)
+}
+
+// decodeHash validates hash against key of scheme and decodes it
+func (s *server) decodeHash(scheme, hash string) (geohash.GeoCryptor, *geohash.LocationBox, error) {
+	sch, err := parseScheme(scheme)
+	if err != nil {
+		return nil, nil, err
+	}
+	h, err := geohash.NewHash(sch, hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if h.Precision() > s.cfg.MaxPrecision {
+		return nil, nil, fmt.Errorf("hash longer than %d", s.cfg.MaxPrecision)
+	}
+	return sch.NewCryptor(), h.Box().(*geohash.LocationBox), nil
+}
+
+func (s *server) checkPrecision(p int) error {
+	if p <= 0 || p > s.cfg.MaxPrecision {
+		return fmt.Errorf("precision must be in [1, %d]", s.cfg.MaxPrecision)
+	}
+	return nil
+}
+
+func checkPoint(lat, lng float64) error {
+	if math.IsNaN(lat) || math.IsNaN(lng) ||
+		lat < geohash.MinLat || lat > geohash.MaxLat || lng < geohash.MinLng || lng > geohash.MaxLng {
+		return fmt.Errorf("point (%v, %v) out of range", lat, lng)
+	}
+	return nil
+}
+
+type encodeRequest struct {
+	Scheme    string  `json:"scheme"`
+	Lat       float64 `json:"lat"`
+	Lng       float64 `json:"lng"`
+	Precision int     `json:"precision"`
+}
+
+type encodeResponse struct {
+	Hash   string               `json:"hash"`
+	LatErr float64              `json:"latErr"`
+	LngErr float64              `json:"lngErr"`
+	Box    *geohash.LocationBox `json:"box"`
+}
+
+func (req *encodeRequest) serve(s *server) (interface{}, error) {
+	c, err := cryptor(req.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkPrecision(req.Precision); err != nil {
+		return nil, err
+	}
+	if err := checkPoint(req.Lat, req.Lng); err != nil {
+		return nil, err
+	}
+	h, latErr, lngErr := c.EncodeWithErr(req.Lat, req.Lng, req.Precision)
+	return encodeResponse{Hash: h, LatErr: latErr, LngErr: lngErr,
+		Box: c.EncodeAsBox(req.Lat, req.Lng, req.Precision).(*geohash.LocationBox)}, nil
+}
+
+type decodeRequest struct {
+	Scheme string `json:"scheme"`
+	Hash   string `json:"hash"`
+}
+
+type decodeResponse struct {
+	Lat    float64              `json:"lat"`
+	Lng    float64              `json:"lng"`
+	LatErr float64              `json:"latErr"`
+	LngErr float64              `json:"lngErr"`
+	Box    *geohash.LocationBox `json:"box"`
+}
+
+func (req *decodeRequest) serve(s *server) (interface{}, error) {
+	c, box, err := s.decodeHash(req.Scheme, req.Hash)
+	if err != nil {
+		return nil, err
+	}
+	lat, lng, latErr, lngErr := c.DecodeWithErr(req.Hash, len(req.Hash))
+	return decodeResponse{Lat: lat, Lng: lng, LatErr: latErr, LngErr: lngErr, Box: box}, nil
+}
+
+type neighborsRequest struct {
+	Scheme string `json:"scheme"`
+	Hash   string `json:"hash"`
+}
+
+type cellsResponse struct {
+	Cells []*geohash.LocationBox `json:"cells"`
+}
+
+func newCellsResponse(boxes []geohash.BoundingBox) cellsResponse {
+	r := cellsResponse{Cells: []*geohash.LocationBox{}}
+	for _, b := range boxes {
+		if lb, ok := b.(*geohash.LocationBox); ok && lb != nil {
+			r.Cells = append(r.Cells, lb)
+		}
+	}
+	return r
+}
+
+func (req *neighborsRequest) serve(s *server) (interface{}, error) {
+	c, _, err := s.decodeHash(req.Scheme, req.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return newCellsResponse(c.Neighbors(req.Hash, len(req.Hash))), nil
+}
+
+type coverRequest struct {
+	Scheme    string `json:"scheme"`
+	Precision int    `json:"precision"`
+	Box       *struct {
+		MinLat float64 `json:"minLat"`
+		MinLng float64 `json:"minLng"`
+		MaxLat float64 `json:"maxLat"`
+		MaxLng float64 `json:"maxLng"`
+	} `json:"box"`
+	Circle *struct {
+		Lat    float64 `json:"lat"`
+		Lng    float64 `json:"lng"`
+		Radius float64 `json:"radius"`
+	} `json:"circle"`
+	Polygon *geohash.Polygon `json:"polygon"`
+}
+
+func (req *coverRequest) serve(s *server) (interface{}, error) {
+	n, cover, err := req.plan(s)
+	if err != nil {
+		return nil, err
+	}
+	if n > float64(s.cfg.MaxCells) {
+		return nil, fmt.Errorf("cover needs about %.0f cells, limit is %d", n, s.cfg.MaxCells)
+	}
+	return newCellsResponse(cover()), nil
+}
+
+// plan validates request and returns estimated cells of cover and how to compute it
+func (req *coverRequest) plan(s *server) (float64, func() []geohash.BoundingBox, error) {
+	c, err := cryptor(req.Scheme)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := s.checkPrecision(req.Precision); err != nil {
+		return 0, nil, err
+	}
+
+	var bounds geohash.LocationBox
+	var cover func() []geohash.BoundingBox
+	switch {
+	case req.Box != nil && req.Circle == nil && req.Polygon == nil:
+		b := req.Box
+		// minLng greater than maxLng crosses the antimeridian
+		if b.MinLat > b.MaxLat {
+			return 0, nil, errors.New("box minLat must not exceed maxLat")
+		}
+		bounds = geohash.LocationBox{MinLat: b.MinLat, MinLng: b.MinLng, MaxLat: b.MaxLat, MaxLng: b.MaxLng}
+		cover = func() []geohash.BoundingBox {
+			return geohash.CoverBox(c, b.MinLat, b.MinLng, b.MaxLat, b.MaxLng, req.Precision)
+		}
+	case req.Circle != nil && req.Box == nil && req.Polygon == nil:
+		ci := req.Circle
+		if ci.Radius < 0 || math.IsNaN(ci.Radius) {
+			return 0, nil, errors.New("radius must not be negative")
+		}
+		if err := checkPoint(ci.Lat, ci.Lng); err != nil {
+			return 0, nil, err
+		}
+		bounds = geohash.CircleBounds(ci.Lat, ci.Lng, ci.Radius)
+		cover = func() []geohash.BoundingBox {
+			return geohash.CoverCircle(c, ci.Lat, ci.Lng, ci.Radius, req.Precision)
+		}
+	case req.Polygon != nil && req.Box == nil && req.Circle == nil:
+		p := *req.Polygon
+		if len(p.Coordinates) == 0 || len(p.Coordinates[0]) < 4 {
+			return 0, nil, errors.New("polygon needs a ring of at least 4 points")
+		}
+		bounds = p.Envelope()
+		cover = func() []geohash.BoundingBox {
+			return geohash.CoverPolygon(c, p, req.Precision)
+		}
+	default:
+		return 0, nil, errors.New("cover needs exactly one of box, circle or polygon")
+	}
+	return estimateCells(c, bounds, req.Precision), cover, nil
+}
+
+// estimateCells returns upper bound of cells covering box, used to reject
+// requests before computing them. Box may cross the antimeridian
+func estimateCells(c geohash.GeoCryptor, bounds geohash.LocationBox, precision int) float64 {
+	lb := c.EncodeAsBox(bounds.MinLat, bounds.MinLng, precision).(*geohash.LocationBox)
+	rows := math.Floor((bounds.MaxLat-bounds.MinLat)/(lb.MaxLat-lb.MinLat)) + 2
+	cols := math.Floor(bounds.Width()/(lb.MaxLng-lb.MinLng)) + 2
+	return rows * cols
+}
+
+type batchRequest struct {
+	Requests []json.RawMessage `json:"requests"`
+}
+
+type batchResult struct {
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// serve decodes every request before running any, so covers of the whole
+// batch together stay within MaxCells
+func (req *batchRequest) serve(s *server) (interface{}, error) {
+	if len(req.Requests) > s.cfg.MaxBatch {
+		return nil, fmt.Errorf("batch has %d requests, limit is %d", len(req.Requests), s.cfg.MaxBatch)
+	}
+	reqs, errs := make([]request, len(req.Requests)), make([]error, len(req.Requests))
+	cells := 0.0
+	for i, raw := range req.Requests {
+		reqs[i], errs[i] = decodeBatchOne(raw)
+		if cr, ok := reqs[i].(*coverRequest); ok && errs[i] == nil {
+			// invalid covers fail alone when served
+			if n, _, err := cr.plan(s); err == nil {
+				cells += n
+			}
+		}
+	}
+	if cells > float64(s.cfg.MaxCells) {
+		return nil, fmt.Errorf("batch covers need about %.0f cells, limit is %d", cells, s.cfg.MaxCells)
+	}
+
+	results := make([]batchResult, 0, len(req.Requests))
+	for i, r := range reqs {
+		var res interface{}
+		err := errs[i]
+		if err == nil {
+			res, err = r.serve(s)
+		}
+		if err != nil {
+			results = append(results, batchResult{Error: err.Error()})
+			continue
+		}
+		results = append(results, batchResult{Result: res})
+	}
+	return map[string]interface{}{"results": results}, nil
+}
+
+// decodeBatchOne decodes request of batch by its op, fields unknown to the op are rejected
+func decodeBatchOne(raw json.RawMessage) (request, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	var op string
+	if v, ok := fields["op"]; ok {
+		if err := json.Unmarshal(v, &op); err != nil {
+			return nil, fmt.Errorf("invalid op: %v", err)
+		}
+		delete(fields, "op")
+	}
+	var r request
+	switch op {
+	case "encode":
+		r = &encodeRequest{}
+	case "decode":
+		r = &decodeRequest{}
+	case "neighbors":
+		r = &neighborsRequest{}
+	case "cover":
+		r = &coverRequest{}
+	default:
+		return nil, fmt.Errorf("unknown op %q", op)
+	}
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
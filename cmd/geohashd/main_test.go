@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	cfg := defaultConfig
+	cfg.MaxBody, cfg.MaxBatch, cfg.MaxCells = 512, 2, 100
+	h := newServer(cfg)
+
+	tr := []struct {
+		Method, Path, Body string
+		Code               int
+		Out                string
+	}{
+		{"GET", "/healthz", "", 200, `{"status":"ok"}`},
+		{"POST", "/healthz", "", 405, `{"error"`},
+		{"GET", "/v1/encode", "", 405, `{"error"`},
+		{"POST", "/v1/encode", `{"lat":-2,"lng":-3,"precision":6}`, 200, `{"hash":"7ztuee","latErr":0.002747,"lngErr":0.005493,"box":{`},
+		{"POST", "/v1/encode", `{"scheme":"geohash36","lat":51.504444,"lng":-0.086666,"precision":10}`, 200, `{"hash":"bdrdC26BqH"`},
+		{"POST", "/v1/encode", `{"lat":-2,"lng":-3,"precision":13}`, 400, `{"error":"precision must be in [1, 12]"}`},
+		{"POST", "/v1/encode", `{"lat":91,"lng":-3,"precision":1}`, 400, `{"error"`},
+		{"POST", "/v1/encode", `{"lat":-2,"lng":-3,"precision":1,"extra":1}`, 400, `{"error"`},
+		{"POST", "/v1/encode", `{"lat":-2,"lng":-3,"precision":1}{}`, 400, `{"error":"unexpected data after request"}`},
+		{"POST", "/v1/encode", `{"lat":-2,"lng":-3,"precision":1}}`, 400, `{"error":"unexpected data after request"}`},
+		{"POST", "/v1/encode", "{\"lat\":-2,\"lng\":-3,\"precision\":1}\n", 200, `{"hash":"7"`},
+		{"POST", "/v1/decode", `{"hash":"7ztuee"}`, 200, `{"lat":-2.002258,"lng":-3.004761,`},
+		{"POST", "/v1/decode", `{"hash":"7ztuea"}`, 400, `{"error"`},
+		{"POST", "/v1/neighbors", `{"hash":"gz"}`, 200, `{"cells":[{`},
+		{"POST", "/v1/cover", `{"precision":1,"box":{"minLat":-1,"minLng":-1,"maxLat":1,"maxLng":1}}`, 200, `{"cells":[{`},
+		{"POST", "/v1/cover", `{"precision":6,"circle":{"lat":-2.002258,"lng":-3.004761,"radius":100}}`, 200, `{"cells":[{"maxLat":-1.99951171875`},
+		{"POST", "/v1/cover", `{"precision":1,"polygon":{"type":"Polygon","coordinates":[[[-44,-44],[44,-44],[-44,44],[-44,-44]]]}}`, 200, `{"cells":[{`},
+		{"POST", "/v1/cover", `{"precision":9,"box":{"minLat":-1,"minLng":-1,"maxLat":1,"maxLng":1}}`, 400, `{"error":"cover needs about`},
+		{"POST", "/v1/cover", `{"precision":1}`, 400, `{"error":"cover needs exactly one`},
+		{"POST", "/v1/cover", `{"precision":1,"box":{"minLat":0,"minLng":170,"maxLat":10,"maxLng":-170}}`, 200,
+			`{"cells":[{"maxLat":45,"minLat":0,"maxLng":180,"minLng":135,`},
+		{"POST", "/v1/cover", `{"precision":1,"box":{"minLat":10,"minLng":0,"maxLat":0,"maxLng":10}}`, 400, `{"error":"box minLat must not exceed maxLat"}`},
+		{"POST", "/v1/batch", `{"requests":[{"op":"encode","lat":-2,"lng":-3,"precision":1},{"op":"nope"}]}`, 200,
+			`{"results":[{"result":{"hash":"7",`},
+		{"POST", "/v1/batch", `{"requests":[{},{},{}]}`, 400, `{"error":"batch has 3 requests, limit is 2"}`},
+		{"POST", "/v1/batch", `{"requests":[{"op":"decode","hash":"7","extra":1},{"op":"decode","hash":"7"}]}`, 200,
+			`{"results":[{"error":"json: unknown field \"extra\""},{"result":{`},
+		// each cover is within the limit, both together are not
+		{"POST", "/v1/batch", `{"requests":[{"op":"cover","precision":3,"box":{"minLat":0,"minLng":0,"maxLat":10,"maxLng":10}},` +
+			`{"op":"cover","precision":3,"box":{"minLat":0,"minLng":0,"maxLat":10,"maxLng":10}}]}`, 400,
+			`{"error":"batch covers need about 162 cells, limit is 100"}`},
+		{"POST", "/v1/batch", `{"requests":[{"op":"cover","precision":3,"box":{"minLat":0,"minLng":0,"maxLat":10,"maxLng":10}}]}`, 200,
+			`{"results":[{"result":{"cells":[{`},
+		// circle bounds wrap around the antimeridian instead of stopping at it
+		{"POST", "/v1/cover", `{"precision":5,"circle":{"lat":0,"lng":179.99,"radius":100000}}`, 400,
+			`{"error":"cover needs about 1764 cells`},
+		{"POST", "/v1/decode", `{"hash":"` + strings.Repeat("7", 600) + `"}`, 413, `{"error"`},
+	}
+
+	for _, v := range tr {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(v.Method, v.Path, strings.NewReader(v.Body)))
+		if rec.Code != v.Code || !strings.HasPrefix(rec.Body.String(), v.Out) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %s %s\n\n\texp: %v %s\n\n\tgot: %v %s\n\n",
+				filepath.Base(file), line, v.Method, v.Path, v.Code, v.Out, rec.Code, rec.Body.String())
+			t.FailNow()
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/batch",
+		strings.NewReader(`{"requests":[{"op":"decode","hash":"7"},{"op":"nope"}]}`)))
+	if !strings.Contains(rec.Body.String(), `{"error":"unknown op \"nope\""}`) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %s\n\n", filepath.Base(file), line, rec.Body.String())
+		t.FailNow()
+	}
+}
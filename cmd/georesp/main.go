@@ -0,0 +1,22 @@
+// Command georesp serves Redis GEO commands from memory over the Redis protocol
+//
+// Usage:
+//
+//	georesp -addr :6379
+//	redis-cli -p 6379 GEOADD Sicily 13.361389 38.115556 Palermo
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/myyang/geohash/resp"
+)
+
+func main() {
+	addr := flag.String("addr", ":6379", "listen address")
+	flag.Parse()
+
+	log.Printf("georesp listening on %s", *addr)
+	log.Fatal(resp.NewServer().ListenAndServe(*addr))
+}
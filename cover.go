@@ -0,0 +1,248 @@
+package geohash
+
+import "math"
+
+// ratio of target cell size below which an overlap is treated as
+// float noise on a shared edge rather than real coverage
+const edgeTolerance = 1e-9
+
+// Parent returns hash of enclosing cell one level up, empty for top level cells
+func Parent(value string) string {
+	if len(value) == 0 {
+		return ""
+	}
+	return value[:len(value)-1]
+}
+
+// Children returns hashes of cells one level down in key order
+func Children(c GeoCryptor, value string) []string {
+	key := c.HashKey()
+	n := make([]string, 0, len(key))
+	for i := 0; i < len(key); i++ {
+		n = append(n, value+key[i:i+1])
+	}
+	return n
+}
+
+// CoverBox returns cells at given precision which together cover
+// the box from (minLat, minLng) to (maxLat, maxLng), ordered row by row
+// from south-west to north-east. A box with minLng greater than maxLng crosses
+// the antimeridian, cells from minLng to 180 come before cells from -180 to maxLng
+func CoverBox(c GeoCryptor, minLat, minLng, maxLat, maxLng float64, precision int) []BoundingBox {
+	if precision <= 0 || minLat > maxLat {
+		return nil
+	}
+	if minLng > maxLng {
+		west := CoverBox(c, minLat, minLng, maxLat, MaxLng, precision)
+		return append(west, CoverBox(c, minLat, MinLng, maxLat, maxLng, precision)...)
+	}
+	minLat, maxLat = maxFloat64(minLat, MinLat), minFloat64(maxLat, MaxLat)
+	minLng, maxLng = maxFloat64(minLng, MinLng), minFloat64(maxLng, MaxLng)
+	return cover(c, &LocationBox{MaxLat: maxLat, MinLat: minLat, MaxLng: maxLng, MinLng: minLng}, precision)
+}
+
+// cover walks the grid of cryptor c row by row and collects every cell
+// which shares a non-empty area with box, degenerated box gets at least one cell
+func cover(c GeoCryptor, box *LocationBox, precision int) []BoundingBox {
+	first := cellAt(c, box.MinLat, box.MinLng, precision)
+	latEps := (first.MaxLat - first.MinLat) * edgeTolerance
+	lngEps := (first.MaxLng - first.MinLng) * edgeTolerance
+
+	n := make([]BoundingBox, 0, 1)
+	for lat := box.MinLat; lat == box.MinLat || box.MaxLat-lat > latEps; {
+		var row *LocationBox
+		for lng := box.MinLng; lng == box.MinLng || box.MaxLng-lng > lngEps; {
+			cell := cellAt(c, lat, lng, precision)
+			if row == nil {
+				row = cell
+			}
+			n = append(n, cell)
+			if cell.MaxLng <= lng {
+				break
+			}
+			lng = cell.MaxLng
+		}
+		if row.MaxLat <= lat {
+			break
+		}
+		lat = row.MaxLat
+	}
+	return n
+}
+
+// cellAt returns the cell which covers the area just north-east of (lat, lng),
+// encoders may assign a point lying on a grid line to either side of it
+func cellAt(c GeoCryptor, lat, lng float64, precision int) *LocationBox {
+	lb := c.EncodeAsBox(lat, lng, precision).(*LocationBox)
+	if lb.MaxLat > lat && lb.MaxLng > lng {
+		return lb
+	}
+	dlat, dlng := 0.0, 0.0
+	if lb.MaxLat <= lat {
+		dlat = (lb.MaxLat - lb.MinLat) / 2
+	}
+	if lb.MaxLng <= lng {
+		dlng = (lb.MaxLng - lb.MinLng) / 2
+	}
+	return c.EncodeAsBox(lat+dlat, lng+dlng, precision).(*LocationBox)
+}
+
+// CoverCircle returns cells at given precision which intersect the circle
+// of radius in meters around (lat, lng), also across the antimeridian
+func CoverCircle(c GeoCryptor, lat, lng, radius float64, precision int) []BoundingBox {
+	if radius < 0 {
+		return nil
+	}
+	bounds := CircleBounds(lat, lng, radius)
+	n := []BoundingBox{}
+	for _, b := range CoverBox(c, bounds.MinLat, bounds.MinLng, bounds.MaxLat, bounds.MaxLng, precision) {
+		lb := b.(*LocationBox)
+		nlat := math.Max(lb.MinLat, math.Min(lat, lb.MaxLat))
+		if Distance(lat, lng, nlat, nearestLng(lng, lb)) <= radius {
+			n = append(n, b)
+		}
+	}
+	return n
+}
+
+// CircleBounds returns bounding box of the circle of radius in meters around (lat, lng).
+// Longitude spans whole range when circle reaches a pole, and the box crosses
+// the antimeridian with MinLng greater than MaxLng when the circle does
+func CircleBounds(lat, lng, radius float64) LocationBox {
+	dlat := radius / EarthRadius * 180 / math.Pi
+	minLat, maxLat := math.Max(lat-dlat, MinLat), math.Min(lat+dlat, MaxLat)
+	r := math.Sin(radius/EarthRadius) / math.Cos(lat*math.Pi/180)
+	if minLat == MinLat || maxLat == MaxLat || r >= 1 {
+		return newBox(minLat, MinLng, maxLat, MaxLng)
+	}
+	dlng := math.Asin(r) * 180 / math.Pi
+	return newBox(minLat, lng-dlng, maxLat, lng+dlng)
+}
+
+// nearestLng returns longitude of box edges or inside which is closest to lng
+// going either way around the globe
+func nearestLng(lng float64, lb *LocationBox) float64 {
+	if lng >= lb.MinLng && lng <= lb.MaxLng {
+		return lng
+	}
+	west := math.Mod(lng-lb.MaxLng+2*MaxLng, 2*MaxLng)
+	east := math.Mod(lb.MinLng-lng+2*MaxLng, 2*MaxLng)
+	if west < east {
+		return lb.MaxLng
+	}
+	return lb.MinLng
+}
+
+// CoverPolygon returns cells at given precision which intersect polygon,
+// the first ring is outer boundary and the others are holes
+func CoverPolygon(c GeoCryptor, p Polygon, precision int) []BoundingBox {
+	if len(p.Coordinates) == 0 {
+		return nil
+	}
+	env := p.Envelope()
+	n := []BoundingBox{}
+	for _, b := range CoverBox(c, env.MinLat, env.MinLng, env.MaxLat, env.MaxLng, precision) {
+		if p.intersectsBox(b.(*LocationBox)) {
+			n = append(n, b)
+		}
+	}
+	return n
+}
+
+// Contains reports whether point lies inside polygon by ray casting
+func (p Polygon) Contains(lat, lng float64) bool {
+	if len(p.Coordinates) == 0 || !ringContains(p.Coordinates[0], lat, lng) {
+		return false
+	}
+	for _, hole := range p.Coordinates[1:] {
+		if ringContains(hole, lat, lng) {
+			return false
+		}
+	}
+	return true
+}
+
+func ringContains(ring [][2]float64, lat, lng float64) bool {
+	in := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi, xj, yj := ring[i][0], ring[i][1], ring[j][0], ring[j][1]
+		if (yi > lat) != (yj > lat) && lng < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			in = !in
+		}
+	}
+	return in
+}
+
+// intersectsBox reports whether polygon and box share any point:
+// a ring vertex lies in box, a ring edge crosses box edge, or box center lies in polygon
+func (p Polygon) intersectsBox(lb *LocationBox) bool {
+	corners := [][2]float64{
+		{lb.MinLng, lb.MinLat}, {lb.MaxLng, lb.MinLat},
+		{lb.MaxLng, lb.MaxLat}, {lb.MinLng, lb.MaxLat},
+	}
+	for _, ring := range p.Coordinates {
+		for i, pt := range ring {
+			if pt[0] >= lb.MinLng && pt[0] <= lb.MaxLng && pt[1] >= lb.MinLat && pt[1] <= lb.MaxLat {
+				return true
+			}
+			if i == 0 {
+				continue
+			}
+			for k := range corners {
+				if segmentsCross(ring[i-1], pt, corners[k], corners[(k+1)%4]) {
+					return true
+				}
+			}
+		}
+	}
+	return p.Contains((lb.MinLat+lb.MaxLat)/2, (lb.MinLng+lb.MaxLng)/2)
+}
+
+// relateCell tells how box lies to polygon: it Overlaps the boundary when a ring
+// edge touches box, otherwise box lies on one side of every edge and its center decides
+func (p Polygon) relateCell(lb *LocationBox) Relation {
+	for _, ring := range p.Coordinates {
+		for i := 1; i < len(ring); i++ {
+			if segmentTouchesBox(ring[i-1], ring[i], lb) {
+				return Overlaps
+			}
+		}
+	}
+	if p.Contains((lb.MinLat+lb.MaxLat)/2, (lb.MinLng+lb.MaxLng)/2) {
+		return Within
+	}
+	return Disjoint
+}
+
+// segmentTouchesBox reports whether segment ab shares any point with box,
+// edges included, by clipping it to box
+func segmentTouchesBox(a, b [2]float64, lb *LocationBox) bool {
+	dlng, dlat := b[0]-a[0], b[1]-a[1]
+	t0, t1 := 0.0, 1.0
+	for _, e := range [][2]float64{
+		{-dlng, a[0] - lb.MinLng}, {dlng, lb.MaxLng - a[0]},
+		{-dlat, a[1] - lb.MinLat}, {dlat, lb.MaxLat - a[1]},
+	} {
+		switch t := e[1] / e[0]; {
+		case e[0] == 0:
+			if e[1] < 0 {
+				return false
+			}
+		case e[0] < 0:
+			t0 = math.Max(t0, t)
+		default:
+			t1 = math.Min(t1, t)
+		}
+	}
+	return t0 <= t1
+}
+
+// segmentsCross reports whether segment ab and cd intersect
+func segmentsCross(a, b, c, d [2]float64) bool {
+	orient := func(p, q, r [2]float64) float64 {
+		return (q[0]-p[0])*(r[1]-p[1]) - (q[1]-p[1])*(r[0]-p[0])
+	}
+	d1, d2 := orient(c, d, a), orient(c, d, b)
+	d3, d4 := orient(a, b, c), orient(a, b, d)
+	return ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0))
+}
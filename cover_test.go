@@ -0,0 +1,145 @@
+package geohash
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestParentChildren(t *testing.T) {
+	if p := Parent("9q8yy"); p != "9q8y" {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, "9q8y", p)
+		t.FailNow()
+	}
+
+	got := Children(NewDefaultGeoHash36(), "bd")
+	if len(got) != 36 || got[0] != "bd2" || got[35] != "bdX" {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %#v\n\n", filepath.Base(file), line, got)
+		t.FailNow()
+	}
+}
+
+func TestCoverBox(t *testing.T) {
+	cryptor := NewDefaultGeoHash()
+	got, exp := []string{}, []string{}
+
+	for _, v := range CoverBox(cryptor, -45, -45, 0, 45, 1) {
+		h, _ := v.Geohash()
+		got = append(got, h)
+	}
+	exp = []string{"7", "k"}
+	if !reflect.DeepEqual(exp, got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+
+	got = []string{}
+	for _, v := range CoverBox(cryptor, 90, 180, 90, 180, 2) {
+		h, _ := v.Geohash()
+		got = append(got, h)
+	}
+	exp = []string{"zz"}
+	if !reflect.DeepEqual(exp, got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+
+	got = []string{}
+	for _, v := range CoverBox(cryptor, -1, -1, 1, 1, 1) {
+		h, _ := v.Geohash()
+		got = append(got, h)
+	}
+	exp = []string{"7", "k", "e", "s"}
+	if !reflect.DeepEqual(exp, got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+}
+
+func TestCoverCircle(t *testing.T) {
+	cryptor := NewDefaultGeoHash()
+	got, exp := []string{}, []string{}
+
+	for _, v := range CoverCircle(cryptor, -2.002258, -3.004761, 100, 6) {
+		h, _ := v.Geohash()
+		got = append(got, h)
+	}
+	exp = []string{"7ztuee"}
+	if !reflect.DeepEqual(exp, got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+
+	// 1km circle reaches all 8 neighbors of 7ztuee but not corners of the 5x5 ring
+	n := len(CoverCircle(cryptor, -2.002258, -3.004761, 1000, 6))
+	if n < 9 || n >= 25 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: 9 <= cells < 25\n\n\tgot: %#v\n\n", filepath.Base(file), line, n)
+		t.FailNow()
+	}
+}
+
+func TestCoverPolygon(t *testing.T) {
+	cryptor := NewDefaultGeoHash()
+	got, exp := []string{}, []string{}
+
+	// triangle over south-west half of "7", hole cuts out nothing at precision 1
+	p, _ := ParseWKTPolygon("POLYGON((-44 -44, 44 -44, -44 44, -44 -44), (-10 -10, -5 -10, -5 -5, -10 -10))")
+	for _, v := range CoverPolygon(cryptor, p, 1) {
+		h, _ := v.Geohash()
+		got = append(got, h)
+	}
+	exp = []string{"7", "k", "e"}
+	if !reflect.DeepEqual(exp, got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+
+	if !p.Contains(-20, -20) || p.Contains(-8, -6) || p.Contains(20, 20) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tunexpected containment\n\n", filepath.Base(file), line)
+		t.FailNow()
+	}
+}
+
+func TestDistance(t *testing.T) {
+	// London to Paris
+	if d := Distance(51.5074, -0.1278, 48.8566, 2.3522); d < 343000 || d > 345000 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: ~344km\n\n\tgot: %#v\n\n", filepath.Base(file), line, d)
+		t.FailNow()
+	}
+}
+
+func TestCircleBounds(t *testing.T) {
+	tr := []struct {
+		Lat, Lng, Radius float64
+		Crosses          bool
+	}{
+		{0, 179.99, 100000, true},
+		{0, -179.99, 100000, true},
+		{0, 0, 100000, false},
+		{89.5, 0, 100000, false},
+	}
+	for _, v := range tr {
+		b := CircleBounds(v.Lat, v.Lng, v.Radius)
+		// the circle reaches as far east as west of its center
+		east := Distance(v.Lat, v.Lng, v.Lat, b.MaxLng)
+		west := Distance(v.Lat, v.Lng, v.Lat, b.MinLng)
+		if b.CrossesAntimeridian() != v.Crosses || b.Width() < 360 && math.Abs(east-west) > 1e-3 {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v\n\n\tgot: %+v\n\n", filepath.Base(file), line, v, b)
+			t.FailNow()
+		}
+	}
+}
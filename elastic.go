@@ -0,0 +1,68 @@
+package geohash
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ESMaxPrecision is the longest geohash Elasticsearch packs into a long
+const ESMaxPrecision = 12
+
+// ESLongEncode packs geohash string into long as Elasticsearch Geohash.longEncode,
+// hash bits are right aligned above the precision stored in the low 4 bits
+func ESLongEncode(value string) (int64, error) {
+	if len(value) > ESMaxPrecision {
+		return 0, fmt.Errorf("geohash %q longer than %d", value, ESMaxPrecision)
+	}
+	if err := validHash(DefaultB32Str, value); err != nil {
+		return 0, err
+	}
+	l := uint64(0)
+	for i := 0; i < len(value); i++ {
+		l = l<<5 | uint64(strings.IndexByte(DefaultB32Str, value[i]))
+	}
+	return int64(l<<4 | uint64(len(value))), nil
+}
+
+// ESStringEncode unpacks long of Elasticsearch Geohash.stringEncode to geohash string
+func ESStringEncode(long int64) (string, error) {
+	l := uint64(long)
+	level := int(l & 15)
+	if level == 0 || level > ESMaxPrecision {
+		return "", fmt.Errorf("invalid geohash long %d: precision %d", long, level)
+	}
+	l >>= 4
+	if level < ESMaxPrecision && l>>uint(level*5) != 0 {
+		return "", fmt.Errorf("invalid geohash long %d: bits beyond precision %d", long, level)
+	}
+	b := make([]byte, level)
+	for i := level - 1; i >= 0; i-- {
+		b[i] = B32[l&31]
+		l >>= 5
+	}
+	return string(b), nil
+}
+
+// ESDecodeAsBox returns cell of Elasticsearch geohash long
+func ESDecodeAsBox(long int64) (BoundingBox, error) {
+	value, err := ESStringEncode(long)
+	if err != nil {
+		return nil, err
+	}
+	return NewDefaultGeoHash().DecodeAsBox(value, len(value)), nil
+}
+
+// ESLongToRedis converts Elasticsearch geohash long to 52-bit integer hash of the cell center
+func ESLongToRedis(long int64) (uint64, error) {
+	value, err := ESStringEncode(long)
+	if err != nil {
+		return 0, err
+	}
+	return GeoHashToRedis(value)
+}
+
+// RedisToESLong converts 52-bit integer hash to Elasticsearch geohash long of given precision,
+// precision is at most 11 as RedisToGeoHash
+func RedisToESLong(bits uint64, precision int) (int64, error) {
+	return ESLongEncode(RedisToGeoHash(bits, precision))
+}
@@ -0,0 +1,77 @@
+package geohash
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestESLong(t *testing.T) {
+	tr := []struct {
+		Hash string
+		Long int64
+	}{
+		{"u", 417},
+		{"u4pruydqqvj", 471008704346830619},
+		{"sqc8b49rny0", 444928549405408267},
+		{"s0000000000", 432345564227567627},
+		{"sqc8b49rny00", -4209030492736487412},
+		{"zzzzzzzzzzzz", -4},
+	}
+	for _, v := range tr {
+		long, err := ESLongEncode(v.Hash)
+		if err != nil || long != v.Long {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %s\n\n\texp: %v\n\n\tgot: %v, %v\n\n", filepath.Base(file), line, v.Hash, v.Long, long, err)
+			t.FailNow()
+		}
+		hash, err := ESStringEncode(v.Long)
+		if err != nil || hash != v.Hash {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %d\n\n\texp: %v\n\n\tgot: %v, %v\n\n", filepath.Base(file), line, v.Long, v.Hash, hash, err)
+			t.FailNow()
+		}
+	}
+
+	for _, v := range []string{"", "a", "0123456789bcd"} {
+		if _, err := ESLongEncode(v); err == nil {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\n\texp error of %q\n\n", filepath.Base(file), line, v)
+			t.FailNow()
+		}
+	}
+	for _, v := range []int64{0, 13, 15, 1<<10 | 1} {
+		if _, err := ESStringEncode(v); err == nil {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\n\texp error of %d\n\n", filepath.Base(file), line, v)
+			t.FailNow()
+		}
+	}
+}
+
+func TestESDecodeAsBox(t *testing.T) {
+	b, err := ESDecodeAsBox(471008704346830619)
+	exp := NewDefaultGeoHash().DecodeAsBox("u4pruydqqvj", 11).(*LocationBox)
+	if got, ok := b.(*LocationBox); err != nil || !ok || *got != *exp {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, exp, b, err)
+		t.FailNow()
+	}
+}
+
+func TestESLongRedis(t *testing.T) {
+	// Palermo of Redis GEOADD documentation
+	long, err := RedisToESLong(3479099956230698, 11)
+	if err != nil || long != 444928549405408267 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %v, %v\n\n", filepath.Base(file), line, long, err)
+		t.FailNow()
+	}
+	bits, err := ESLongToRedis(444928549405408267)
+	if err != nil || RedisString(bits) != "sqc8b49rny0" {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %v, %v\n\n", filepath.Base(file), line, bits, err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,47 @@
+package geohash
+
+// encloseMaxPrecision bounds the search for degenerated boxes such as a single point
+const encloseMaxPrecision = 12
+
+// EncloseBox returns the deepest cell which fully contains box, that is the longest
+// common prefix of every point in it, up to precision 12. Points on cell edges belong
+// to the cells the encoder assigns them to, so a box lying exactly on cell "s" from
+// (0, 0) to (45, 45) has its south-west corner in "7" and gets the whole world with
+// empty hash. So does a box crossing a top level cell edge or the antimeridian,
+// longitudes are wrapped into [-180, 180]. An invalid box gets nil
+func EncloseBox(c GeoCryptor, box LocationBox) BoundingBox {
+	if !(box.MinLat <= box.MaxLat && box.MinLat >= MinLat && box.MaxLat <= MaxLat) {
+		return nil
+	}
+	world := &LocationBox{MaxLat: MaxLat, MinLat: MinLat, MaxLng: MaxLng, MinLng: MinLng,
+		LatErr: MaxLat, LngErr: MaxLng}
+	if box = box.Normalize(); box.CrossesAntimeridian() {
+		return world
+	}
+	// cells split both axes into intervals, box lies in one cell
+	// when its south-west and north-east corners do
+	sw := c.Encode(box.MinLat, box.MinLng, encloseMaxPrecision)
+	ne := c.Encode(box.MaxLat, box.MaxLng, encloseMaxPrecision)
+	p := 0
+	for p < len(sw) && p < len(ne) && sw[p] == ne[p] {
+		p++
+	}
+	if p == 0 {
+		return world
+	}
+	return c.DecodeAsBox(sw[:p], p)
+}
+
+// EnclosePoints returns the deepest cell which contains every point as EncloseBox
+// of their envelope, nil for no points
+func EnclosePoints(c GeoCryptor, points []GeoPoint) BoundingBox {
+	if len(points) == 0 {
+		return nil
+	}
+	box := LocationBox{MaxLat: points[0].Lat, MinLat: points[0].Lat, MaxLng: points[0].Lng, MinLng: points[0].Lng}
+	for _, p := range points[1:] {
+		box.MaxLat, box.MinLat = maxFloat64(box.MaxLat, p.Lat), minFloat64(box.MinLat, p.Lat)
+		box.MaxLng, box.MinLng = maxFloat64(box.MaxLng, p.Lng), minFloat64(box.MinLng, p.Lng)
+	}
+	return EncloseBox(c, box)
+}
@@ -0,0 +1,86 @@
+package geohash
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestEncloseBox(t *testing.T) {
+	gh, gh36 := NewDefaultGeoHash(), NewDefaultGeoHash36()
+	tr := []struct {
+		C                              GeoCryptor
+		MinLat, MinLng, MaxLat, MaxLng float64
+		Exp                            string
+	}{
+		// exactly cell "s", its min edges belong to the cells below as Encode has it
+		{gh, 0, 0, 45, 45, ""},
+		{gh, 0.1, 0.1, 45, 45, "s"},
+		{gh, 0, 0.1, 45, 45, ""},
+		{gh, -90, 0.1, 45, 45, ""},
+		{gh, 0, 0, 45.1, 45, ""},
+		{gh, -1, -1, 1, 1, ""},
+		{gh, 57.64911, 10.40744, 57.64911, 10.40744, "u4pruydqqvj8"},
+		{gh, 57.6, 10.3, 57.7, 10.5, "u4"},
+		{gh, -90, -180, 90, 180, ""},
+		{gh36, 60, -180, 90, -120, "2"},
+	}
+	for _, v := range tr {
+		b := EncloseBox(v.C, LocationBox{MinLat: v.MinLat, MinLng: v.MinLng, MaxLat: v.MaxLat, MaxLng: v.MaxLng})
+		lb, ok := b.(*LocationBox)
+		if !ok || lb.Hash != v.Exp || lb.Precision != len(v.Exp) ||
+			lb.MinLat > v.MinLat || lb.MaxLat < v.MaxLat || lb.MinLng > v.MinLng || lb.MaxLng < v.MaxLng {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, v, v.Exp, b)
+			t.FailNow()
+		}
+	}
+
+	// crossing the antimeridian takes the whole world
+	if b := EncloseBox(gh, LocationBox{MinLat: 0, MinLng: 170, MaxLat: 10, MaxLng: -170}); b == nil || b.(*LocationBox).Hash != "" {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: world\n\n\tgot: %#v\n\n", filepath.Base(file), line, b)
+		t.FailNow()
+	}
+	if b := EncloseBox(gh, LocationBox{MinLat: 1, MaxLat: 0}); b != nil {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: nil\n\n\tgot: %#v\n\n", filepath.Base(file), line, b)
+		t.FailNow()
+	}
+}
+
+func TestEnclosePoints(t *testing.T) {
+	gh := NewDefaultGeoHash()
+	b := EnclosePoints(gh, []GeoPoint{{57.64911, 10.40744}, {57.65, 10.41}, {57.6, 10.3}})
+	if h, _ := b.Geohash(); h != "u4pr" {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, "u4pr", b)
+		t.FailNow()
+	}
+	// the enclosing cell holds every point as Encode assigns it
+	for _, v := range []struct {
+		Points []GeoPoint
+		Exp    string
+	}{
+		{[]GeoPoint{{0, 0}, {45, 45}}, ""},
+		{[]GeoPoint{{0.5, 0.5}, {45, 45}}, "s"},
+		{[]GeoPoint{{0, 0}, {-44, -44}}, "7"},
+		{[]GeoPoint{{0, 0}}, gh.Encode(0, 0, encloseMaxPrecision)},
+	} {
+		b := EnclosePoints(gh, v.Points)
+		h, _ := b.Geohash()
+		for _, pt := range v.Points {
+			if h != v.Exp || gh.Encode(pt.Lat, pt.Lng, len(h)) != h {
+				_, file, line, _ := runtime.Caller(0)
+				fmt.Printf("%s:%d: %v\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, v.Points, v.Exp, h)
+				t.FailNow()
+			}
+		}
+	}
+	if b := EnclosePoints(gh, nil); b != nil {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: nil\n\n\tgot: %#v\n\n", filepath.Base(file), line, b)
+		t.FailNow()
+	}
+}
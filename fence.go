@@ -0,0 +1,384 @@
+package geohash
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultFencePrecision is the geohash length fences are indexed at,
+// cells are about 4.9 by 4.9 km
+const DefaultFencePrecision = 5
+
+// Shape is an area a fence is made of: Circle, LocationBox or Polygon,
+// or a pointer to one of them
+type Shape interface {
+	Contains(lat, lng float64) bool
+}
+
+// cellRelater is a shape which tells whether a cell lies Within it,
+// is Disjoint from it or Overlaps its boundary
+type cellRelater interface {
+	relateCell(lb *LocationBox) Relation
+}
+
+// Circle is the area within Radius meters of its center
+type Circle struct {
+	Lat    float64 `json:"lat"`
+	Lng    float64 `json:"lng"`
+	Radius float64 `json:"radius"`
+}
+
+// Contains reports whether point lies inside circle or on its edge
+func (ci Circle) Contains(lat, lng float64) bool {
+	return Distance(ci.Lat, ci.Lng, lat, lng) <= ci.Radius
+}
+
+func (ci Circle) relateCell(lb *LocationBox) Relation {
+	if ci.distanceToBox(lb) > ci.Radius {
+		return Disjoint
+	}
+	// distance grows towards the corners unless an edge passes the antipodal meridian
+	if anti := wrapLng(ci.Lng+MaxLng, false); anti > lb.MinLng && anti < lb.MaxLng {
+		return Overlaps
+	}
+	for _, lat := range []float64{lb.MinLat, lb.MaxLat} {
+		for _, lng := range []float64{lb.MinLng, lb.MaxLng} {
+			if !ci.Contains(lat, lng) {
+				return Overlaps
+			}
+		}
+	}
+	return Within
+}
+
+// distanceToBox returns meters from center to the nearest point of box
+func (ci Circle) distanceToBox(lb *LocationBox) float64 {
+	if lb.Contains(ci.Lat, ci.Lng) {
+		return 0
+	}
+	rad := math.Pi / 180
+	d := math.Inf(1)
+	for _, lng := range []float64{lb.MinLng, lb.MaxLng} {
+		// nearest point of the meridian, beyond a quarter turn it is a pole
+		lat := math.Atan2(math.Sin(ci.Lat*rad), math.Cos(ci.Lat*rad)*math.Cos((lng-ci.Lng)*rad)) / rad
+		lat = math.Max(lb.MinLat, math.Min(lat, lb.MaxLat))
+		d = math.Min(d, Distance(ci.Lat, ci.Lng, lat, lng))
+	}
+	for _, lat := range []float64{lb.MinLat, lb.MaxLat} {
+		d = math.Min(d, Distance(ci.Lat, ci.Lng, lat, nearestLng(ci.Lng, lb)))
+	}
+	return d
+}
+
+func (lb LocationBox) relateCell(cell *LocationBox) Relation {
+	parts, r := lb.split(), Disjoint
+	for i := range parts {
+		switch relateBoxes(cell, &parts[i]) {
+		case Within, Equal:
+			return Within
+		case Contains, Overlaps:
+			r = Overlaps
+		case Adjacent:
+			// a box without area only ever touches cells
+			if parts[i].MinLat == parts[i].MaxLat || parts[i].MinLng == parts[i].MaxLng {
+				r = Overlaps
+			}
+		}
+	}
+	return r
+}
+
+// Fence is an area registered to Geofences by ID
+type Fence struct {
+	ID    string
+	Shape Shape
+}
+
+// FenceEventType tells what an object did with a fence
+type FenceEventType int
+
+// fence events, Dwell follows Enter once the object stays long enough
+const (
+	FenceEnter FenceEventType = iota
+	FenceExit
+	FenceDwell
+)
+
+var fenceEventNames = []string{"enter", "exit", "dwell"}
+
+func (ft FenceEventType) String() string {
+	if ft < 0 || int(ft) >= len(fenceEventNames) {
+		return fmt.Sprintf("FenceEventType(%d)", int(ft))
+	}
+	return fenceEventNames[ft]
+}
+
+// FenceEvent is emitted by Geofences.Update
+type FenceEvent struct {
+	Type     FenceEventType
+	ObjectID string
+	FenceID  string
+	Time     time.Time
+}
+
+// GeofenceOptions configures Geofences
+type GeofenceOptions struct {
+	// Precision is the hash length fences are indexed at, DefaultFencePrecision if 0.
+	// Larger fences need more cells at higher precision, points are checked
+	// against fence shapes exactly regardless of it
+	Precision int
+	// Dwell is how long an object stays in a fence before FenceDwell, none if 0
+	Dwell time.Duration
+}
+
+// Geofences is a registry of fences which locates points and tracks objects
+// entering, leaving and dwelling in fences. It is safe for concurrent use
+type Geofences struct {
+	c   GeoCryptor
+	opt GeofenceOptions
+
+	mu     sync.RWMutex
+	fences map[string]Fence
+	// index maps cells of every fence, merged by HashSet, to fence ids
+	index map[string][]string
+	cells map[string][]string
+
+	omu     sync.Mutex
+	objects map[string]map[string]*fenceVisit
+}
+
+type fenceVisit struct {
+	since  time.Time
+	dwells bool
+}
+
+// NewGeofences returns empty registry indexing fences with cells of cryptor c
+func NewGeofences(c GeoCryptor, opt GeofenceOptions) (*Geofences, error) {
+	if opt.Precision == 0 {
+		opt.Precision = DefaultFencePrecision
+	}
+	if opt.Precision < 0 {
+		return nil, fmt.Errorf("invalid fence precision of %d", opt.Precision)
+	}
+	if err := CheckPrecision(c, opt.Precision); err != nil {
+		return nil, err
+	}
+	return &Geofences{c: c, opt: opt, fences: map[string]Fence{}, index: map[string][]string{},
+		cells: map[string][]string{}, objects: map[string]map[string]*fenceVisit{}}, nil
+}
+
+// Add registers fence, a fence of the same ID is replaced.
+// Pointer shapes are copied, changing them later does not move the fence
+func (g *Geofences) Add(f Fence) error {
+	switch s := f.Shape.(type) {
+	case *Circle:
+		if s != nil {
+			f.Shape = *s
+		}
+	case *LocationBox:
+		if s != nil {
+			f.Shape = *s
+		}
+	case *Polygon:
+		if s != nil {
+			f.Shape = *s
+		}
+	}
+	switch s := f.Shape.(type) {
+	case Circle:
+		if s.Radius < 0 {
+			return fmt.Errorf("fence %q: negative radius %v", f.ID, s.Radius)
+		}
+	case LocationBox:
+		if s.validBox() != nil || s.MinLat > s.MaxLat {
+			return fmt.Errorf("fence %q: %v", f.ID, CoordinateError{LB: s})
+		}
+	case Polygon:
+		if len(s.Coordinates) == 0 || len(s.Coordinates[0]) < 3 {
+			return fmt.Errorf("fence %q: polygon without outer ring", f.ID)
+		}
+	default:
+		return fmt.Errorf("fence %q: unsupported shape %T", f.ID, f.Shape)
+	}
+	set := NewHashSet(g.c)
+	set.Add(g.cover(f.Shape.(cellRelater))...)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.remove(f.ID)
+	g.fences[f.ID] = f
+	g.cells[f.ID] = set.Hashes()
+	for _, h := range g.cells[f.ID] {
+		g.index[h] = append(g.index[h], f.ID)
+	}
+	return nil
+}
+
+// cover returns cells covering shape top down: cells within shape are kept whole
+// and only cells on its boundary are split, down to the indexed precision
+func (g *Geofences) cover(s cellRelater) []string {
+	n := []string{}
+	var visit func(h string)
+	visit = func(h string) {
+		for _, child := range Children(g.c, h) {
+			switch s.relateCell(g.c.DecodeAsBox(child, len(child)).(*LocationBox)) {
+			case Within:
+				n = append(n, child)
+			case Overlaps:
+				if len(child) < g.opt.Precision {
+					visit(child)
+				} else {
+					n = append(n, child)
+				}
+			}
+		}
+	}
+	visit("")
+	return n
+}
+
+// Remove unregisters fence, objects inside it leave without FenceExit
+func (g *Geofences) Remove(id string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, ok := g.fences[id]
+	g.remove(id)
+	return ok
+}
+
+func (g *Geofences) remove(id string) {
+	for _, h := range g.cells[id] {
+		ids := g.index[h][:0]
+		for _, v := range g.index[h] {
+			if v != id {
+				ids = append(ids, v)
+			}
+		}
+		if len(ids) == 0 {
+			delete(g.index, h)
+		} else {
+			g.index[h] = ids
+		}
+	}
+	delete(g.cells, id)
+	delete(g.fences, id)
+}
+
+// Fence returns registered fence of id
+func (g *Geofences) Fence(id string) (Fence, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	f, ok := g.fences[id]
+	return f, ok
+}
+
+// Locate returns ids of fences containing point in ascending order.
+// Candidates come from the indexed cell of point and every prefix of it,
+// a point near a cell edge also looks at the cells across the edge
+func (g *Geofences) Locate(lat, lng float64) []string {
+	if !(lat >= MinLat && lat <= MaxLat && lng >= MinLng && lng <= MaxLng) {
+		return []string{}
+	}
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	seen, n := map[string]bool{}, []string{}
+	for _, h := range g.candidateCells(lat, lng) {
+		for i := 0; i <= len(h); i++ {
+			for _, id := range g.index[h[:i]] {
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+				if g.fences[id].Shape.Contains(lat, lng) {
+					n = append(n, id)
+				}
+			}
+		}
+	}
+	sort.Strings(n)
+	return n
+}
+
+// candidateCells returns cell of point and, when point lies within edgeTolerance
+// of its edges, cells across them since covers skip cells which only touch a shape
+func (g *Geofences) candidateCells(lat, lng float64) []string {
+	lb := g.c.EncodeAsBox(lat, lng, g.opt.Precision).(*LocationBox)
+	h, w := lb.MaxLat-lb.MinLat, lb.MaxLng-lb.MinLng
+	nudges := func(v, min, max, size float64) []float64 {
+		n := []float64{0}
+		if v-min <= size*edgeTolerance {
+			n = append(n, -size/2)
+		}
+		if max-v <= size*edgeTolerance {
+			n = append(n, size/2)
+		}
+		return n
+	}
+	cells := []string{lb.Hash}
+	for _, dlat := range nudges(lat, lb.MinLat, lb.MaxLat, h) {
+		for _, dlng := range nudges(lng, lb.MinLng, lb.MaxLng, w) {
+			if (dlat == 0 && dlng == 0) || lat+dlat < MinLat || lat+dlat > MaxLat {
+				continue
+			}
+			cells = append(cells, g.c.Encode(lat+dlat, wrapLng(lng+dlng, false), g.opt.Precision))
+		}
+	}
+	return cells
+}
+
+// Update moves object to point at time t and returns its events: exits, enters and
+// dwells, each ordered by fence id. Objects seen for the first time enter every
+// fence containing them, updates are expected in time order per object
+func (g *Geofences) Update(objectID string, lat, lng float64, t time.Time) []FenceEvent {
+	inside := g.Locate(lat, lng)
+
+	g.omu.Lock()
+	defer g.omu.Unlock()
+	visits := g.objects[objectID]
+	if visits == nil {
+		visits = map[string]*fenceVisit{}
+		g.objects[objectID] = visits
+	}
+	current := make(map[string]bool, len(inside))
+	for _, id := range inside {
+		current[id] = true
+	}
+
+	exits, enters, dwells := []FenceEvent{}, []FenceEvent{}, []FenceEvent{}
+	for id := range visits {
+		if current[id] {
+			continue
+		}
+		delete(visits, id)
+		if _, ok := g.Fence(id); ok {
+			exits = append(exits, FenceEvent{Type: FenceExit, ObjectID: objectID, FenceID: id, Time: t})
+		}
+	}
+	for _, id := range inside {
+		v, ok := visits[id]
+		if !ok {
+			v = &fenceVisit{since: t}
+			visits[id] = v
+			enters = append(enters, FenceEvent{Type: FenceEnter, ObjectID: objectID, FenceID: id, Time: t})
+		}
+		if g.opt.Dwell > 0 && !v.dwells && t.Sub(v.since) >= g.opt.Dwell {
+			v.dwells = true
+			dwells = append(dwells, FenceEvent{Type: FenceDwell, ObjectID: objectID, FenceID: id, Time: t})
+		}
+	}
+	if len(visits) == 0 {
+		delete(g.objects, objectID)
+	}
+	sort.Slice(exits, func(i, j int) bool { return exits[i].FenceID < exits[j].FenceID })
+	return append(append(exits, enters...), dwells...)
+}
+
+// Forget drops tracking state of object without events
+func (g *Geofences) Forget(objectID string) {
+	g.omu.Lock()
+	defer g.omu.Unlock()
+	delete(g.objects, objectID)
+}
@@ -0,0 +1,197 @@
+package geohash
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+)
+
+func newTestFences(t *testing.T, opt GeofenceOptions) *Geofences {
+	g, err := NewGeofences(NewDefaultGeoHash(), opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fences := []Fence{
+		{ID: "sf", Shape: Circle{Lat: 37.7749, Lng: -122.4194, Radius: 10000}},
+		{ID: "bay", Shape: LocationBox{MinLat: 37.2, MinLng: -122.6, MaxLat: 38.2, MaxLng: -121.7}},
+		{ID: "pacific", Shape: LocationBox{MinLat: -10, MinLng: 170, MaxLat: 10, MaxLng: -170}},
+		{ID: "dateline", Shape: Circle{Lat: 0, Lng: 179.99, Radius: 5000}},
+		{ID: "triangle", Shape: Polygon{Type: "Polygon", Coordinates: [][][2]float64{
+			{{-45, -45}, {0, -45}, {-45, 0}, {-45, -45}}}}},
+		{ID: "cell", Shape: LocationBox{MinLat: 0, MinLng: 0, MaxLat: 45, MaxLng: 45}},
+	}
+	for _, f := range fences {
+		if err := g.Add(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return g
+}
+
+func TestGeofencesLocate(t *testing.T) {
+	g := newTestFences(t, GeofenceOptions{Precision: 3})
+	tr := []struct {
+		Lat, Lng float64
+		Exp      []string
+	}{
+		{37.7749, -122.4194, []string{"bay", "sf"}},
+		{37.3382, -121.8863, []string{"bay"}},
+		{34.0522, -118.2437, []string{}},
+		{0, -179.99, []string{"dateline", "pacific"}},
+		{5, 175, []string{"pacific"}},
+		{-40, -40, []string{"triangle"}},
+		{-5, -5, []string{}},
+		// edges and corners of a fence lying on cell edges
+		{0, 10, []string{"cell"}},
+		{0, 0, []string{"cell"}},
+		{45, 0, []string{"cell"}},
+		{45, 45, []string{"cell"}},
+		{45.001, 45, []string{}},
+		{91, 0, []string{}},
+	}
+	for _, v := range tr {
+		if got := g.Locate(v.Lat, v.Lng); !reflect.DeepEqual(got, v.Exp) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: (%v, %v)\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, v.Lat, v.Lng, v.Exp, got)
+			t.FailNow()
+		}
+	}
+
+	if !g.Remove("sf") || g.Remove("sf") {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d: remove\n\n", filepath.Base(file), line)
+		t.FailNow()
+	}
+	g.Add(Fence{ID: "bay", Shape: Circle{Lat: 37.3382, Lng: -121.8863, Radius: 1000}})
+	if got := g.Locate(37.7749, -122.4194); len(got) != 0 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %#v\n\n", filepath.Base(file), line, got)
+		t.FailNow()
+	}
+}
+
+func TestGeofencesErrors(t *testing.T) {
+	if _, err := NewGeofences(NewDefaultGeoHash(), GeofenceOptions{Precision: -1}); err == nil {
+		t.Fatal("negative precision accepted")
+	}
+	if _, err := NewGeofences(NewDefaultGeoHash36(), GeofenceOptions{Precision: 20}); err == nil {
+		t.Fatal("precision beyond cryptor accepted")
+	}
+	g, _ := NewGeofences(NewDefaultGeoHash(), GeofenceOptions{})
+	for _, f := range []Fence{
+		{ID: "neg", Shape: Circle{Radius: -1}},
+		{ID: "box", Shape: LocationBox{MinLat: 10, MaxLat: 0}},
+		{ID: "empty", Shape: Polygon{}},
+		{ID: "nil"},
+	} {
+		if err := g.Add(f); err == nil {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: fence %q accepted\n\n", filepath.Base(file), line, f.ID)
+			t.FailNow()
+		}
+	}
+}
+
+func TestGeofencesUpdate(t *testing.T) {
+	g := newTestFences(t, GeofenceOptions{Precision: 4, Dwell: 5 * time.Minute})
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := func(evs []FenceEvent) []string {
+		n := []string{}
+		for _, e := range evs {
+			n = append(n, e.Type.String()+" "+e.FenceID)
+		}
+		return n
+	}
+	steps := []struct {
+		Lat, Lng float64
+		At       time.Duration
+		Exp      []string
+	}{
+		{34.0522, -118.2437, 0, []string{}},
+		{37.3382, -121.8863, time.Minute, []string{"enter bay"}},
+		{37.7749, -122.4194, 2 * time.Minute, []string{"enter sf"}},
+		{37.7750, -122.4195, 6 * time.Minute, []string{"dwell bay"}},
+		{37.7751, -122.4196, 7 * time.Minute, []string{"dwell sf"}},
+		{37.7752, -122.4197, 8 * time.Minute, []string{}},
+		{37.3382, -121.8863, 9 * time.Minute, []string{"exit sf"}},
+		{34.0522, -118.2437, 10 * time.Minute, []string{"exit bay"}},
+		{37.7749, -122.4194, 11 * time.Minute, []string{"enter bay", "enter sf"}},
+	}
+	for _, v := range steps {
+		if got := events(g.Update("truck", v.Lat, v.Lng, t0.Add(v.At))); !reflect.DeepEqual(got, v.Exp) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, v.At, v.Exp, got)
+			t.FailNow()
+		}
+	}
+
+	// removed fences are left silently, forgotten objects enter again
+	g.Remove("sf")
+	if got := events(g.Update("truck", 37.7749, -122.4194, t0.Add(12*time.Minute))); len(got) != 0 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %#v\n\n", filepath.Base(file), line, got)
+		t.FailNow()
+	}
+	g.Forget("truck")
+	ev := g.Update("truck", 37.7749, -122.4194, t0.Add(13*time.Minute))
+	exp := []FenceEvent{{Type: FenceEnter, ObjectID: "truck", FenceID: "bay", Time: t0.Add(13 * time.Minute)}}
+	if !reflect.DeepEqual(ev, exp) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, ev)
+		t.FailNow()
+	}
+}
+
+func TestGeofencesCover(t *testing.T) {
+	g := newTestFences(t, GeofenceOptions{})
+	g.Add(Fence{ID: "world", Shape: &LocationBox{MinLat: MinLat, MinLng: MinLng, MaxLat: MaxLat, MaxLng: MaxLng}})
+	big := &Circle{Lat: 50, Lng: 10, Radius: 1000000}
+	g.Add(Fence{ID: "big", Shape: big})
+	g.Add(Fence{ID: "square", Shape: &Polygon{Type: "Polygon", Coordinates: [][][2]float64{
+		{{-100, 20}, {-80, 20}, {-80, 40}, {-100, 40}, {-100, 20}}}}})
+	// changing a pointer shape after adding does not move the fence
+	big.Lat = -50
+
+	// cells within a fence are indexed whole
+	for id, exp := range map[string][]string{"cell": {"s"}, "world": {""}} {
+		if got := g.cells[id]; !reflect.DeepEqual(got, exp) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %s\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, id, exp, got)
+			t.FailNow()
+		}
+	}
+	if n, all := len(g.cells["big"]), len(CoverCircle(g.c, 50, 10, 1000000, DefaultFencePrecision)); n*10 > all {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\ttoo many cells: %d of %d\n\n", filepath.Base(file), line, n, all)
+		t.FailNow()
+	}
+
+	rand.Seed(42)
+	for i := 0; i < 20000; i++ {
+		lat, lng := rand.Float64()*180-90, rand.Float64()*360-180
+		exp := []string{}
+		g.mu.RLock()
+		for id, f := range g.fences {
+			if f.Shape.Contains(lat, lng) {
+				exp = append(exp, id)
+			}
+		}
+		g.mu.RUnlock()
+		sort.Strings(exp)
+		if got := g.Locate(lat, lng); !reflect.DeepEqual(got, exp) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: (%v, %v)\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, lat, lng, exp, got)
+			t.FailNow()
+		}
+	}
+	if got := g.Locate(50, 10); !reflect.DeepEqual(got, []string{"big", "world"}) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %#v\n\n", filepath.Base(file), line, got)
+		t.FailNow()
+	}
+}
@@ -2,6 +2,7 @@ package geohash
 
 import (
 	"fmt"
+	"math"
 )
 
 // fixed constants
@@ -12,6 +13,13 @@ const (
 	MinLng         = -180.0
 )
 
+// longest hashes standard cryptors encode and decode correctly with float64,
+// longer ones need GeoHashBig
+const (
+	MaxGeoHashPrecision   = 16
+	MaxGeoHash36Precision = 16
+)
+
 // GeoCryptor defines geohash provider
 type GeoCryptor interface {
 	HashKey() string
@@ -32,20 +40,27 @@ type BoundingBox interface {
 	Geohash() (string, error)
 }
 
-// LocationBox stores rectangle shape lcoation info and supports bounding box
+// LocationBox stores rectangle shape lcoation info and supports bounding box,
+// a box with MinLng greater than MaxLng crosses the antimeridian
 type LocationBox struct {
-	MaxLat, MinLat, MaxLng, MinLng float64
-	LatErr, LngErr                 float64
-	Hash                           string
-	Precision                      int
+	MaxLat    float64 `json:"maxLat"`
+	MinLat    float64 `json:"minLat"`
+	MaxLng    float64 `json:"maxLng"`
+	MinLng    float64 `json:"minLng"`
+	LatErr    float64 `json:"latErr"`
+	LngErr    float64 `json:"lngErr"`
+	Hash      string  `json:"hash"`
+	Precision int     `json:"precision"`
 }
 
-// GetCenter return center of rectangle
+// GetCenter return center of rectangle rounded to Precision decimals,
+// see Center and RoundedCenter for exact or explicitly rounded center
 func (lb LocationBox) GetCenter() (float64, float64, error) {
 	if err := lb.validBox(); err != nil {
 		return -999.0, -999.0, err
 	}
-	return roundFloat64((lb.MaxLat+lb.MinLat)/2, lb.Precision), roundFloat64((lb.MaxLng+lb.MinLng)/2, lb.Precision), nil
+	lat, lng := lb.Normalize().Center()
+	return roundFloat64(lat, lb.Precision), roundFloat64(lng, lb.Precision), nil
 }
 
 // ErrPair returns estimated error distance pair in degree
@@ -61,25 +76,14 @@ func (lb LocationBox) Geohash() (string, error) {
 	return lb.Hash, nil
 }
 
+// validBox accepts latitudes within range and any finite longitudes,
+// which Normalize wraps around the antimeridian
 func (lb LocationBox) validBox() error {
 	if lb.MaxLat > MaxLat || lb.MinLat > MaxLat || lb.MinLat < MinLat || lb.MaxLat < MinLat {
 		return CoordinateError{LB: lb}
 	}
-
-	if lb.MaxLng > MaxLng || lb.MinLng > MaxLng {
-		if lb.MaxLng > lb.MinLng {
-			lb.MinLng = lb.MinLng + MinLng*2
-			lb.MaxLng = lb.MinLng + lb.LngErr*2
-		} else {
-			return CoordinateError{LB: lb}
-		}
-	} else if lb.MinLng < MinLng || lb.MaxLng < MinLng {
-		if lb.MinLng < lb.MaxLng {
-			lb.MaxLng = lb.MaxLng + MaxLng*2
-			lb.MinLng = lb.MinLng - lb.LngErr*2
-		} else {
-			return CoordinateError{LB: lb}
-		}
+	if math.IsNaN(lb.MinLng) || math.IsNaN(lb.MaxLng) || math.IsInf(lb.MinLng, 0) || math.IsInf(lb.MaxLng, 0) {
+		return CoordinateError{LB: lb}
 	}
 	return nil
 }
@@ -94,3 +98,32 @@ func (ce CoordinateError) Error() string {
 		"Wrong coordinate:\nmaxLat: %v,  minLat: %v, maxLng: %v, minLng: %v",
 		ce.LB.MaxLat, ce.LB.MinLat, ce.LB.MaxLng, ce.LB.MinLng)
 }
+
+// PrecisionError reports a precision beyond what a cryptor can represent
+type PrecisionError struct {
+	Precision int
+	Max       int
+}
+
+func (pe PrecisionError) Error() string {
+	return fmt.Sprintf("precision %d exceeds %d supported by cryptor", pe.Precision, pe.Max)
+}
+
+// checkLength returns PrecisionError for the first length beyond max
+func checkLength(max int, lengths ...int) error {
+	for _, l := range lengths {
+		if l > max {
+			return PrecisionError{Precision: l, Max: max}
+		}
+	}
+	return nil
+}
+
+// CheckPrecision returns PrecisionError when cryptor c cannot represent hashes of precision,
+// cryptors without MaxPrecision method are not limited
+func CheckPrecision(c GeoCryptor, precision int) error {
+	if m, ok := c.(interface{ MaxPrecision() int }); ok && precision > m.MaxPrecision() {
+		return PrecisionError{Precision: precision, Max: m.MaxPrecision()}
+	}
+	return nil
+}
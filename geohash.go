@@ -1,9 +1,9 @@
 package geohash
 
-import "bytes"
-
 import "log"
 
+import "sync"
+
 // fixed constants
 const (
 	ByteWidth     int = 4
@@ -17,11 +17,77 @@ var (
 	HalfByteWidth = (ByteWidth / 2) + 1
 )
 
+// bufPool recycles hash buffers of encoders, only the returned string is allocated
+var bufPool = sync.Pool{New: func() interface{} {
+	b := make([]byte, 0, 16)
+	return &b
+}}
+
+// fastPrecision is the longest hash handled on integers, every edge of its
+// grid is exact in float64, longer hashes are bisected as before
+const fastPrecision = 12
+
 func encode(latitude, longitude float64,
 	key []byte, precision int) (string, float64, float64, float64, float64) {
+	bp := bufPool.Get().(*[]byte)
+	bf, maxLat, minLat, maxLng, minLng := appendEncode((*bp)[:0], latitude, longitude, key, precision)
+	hash := string(bf)
+	*bp = bf
+	bufPool.Put(bp)
+	return hash, maxLat, minLat, maxLng, minLng
+}
+
+// appendEncode appends hash to dst and returns extended buffer with cell edges
+func appendEncode(dst []byte, latitude, longitude float64,
+	key []byte, precision int) ([]byte, float64, float64, float64, float64) {
+	if precision > fastPrecision {
+		return appendEncodeBisect(dst, latitude, longitude, key, precision)
+	}
+	if precision < 0 {
+		precision = 0
+	}
+
+	bits := uint(precision * 5)
+	latIdx, minLat, maxLat := quantize(latitude, MinLat, MaxLat, bits/2)
+	lngIdx, minLng, maxLng := quantize(longitude, MinLng, MaxLng, bits-bits/2)
+	code := spread32(latIdx) | spread32(lngIdx)<<1
+	if bits%2 == 1 {
+		code = spread32(lngIdx) | spread32(latIdx)<<1
+	}
+
+	for i := precision - 1; i >= 0; i-- {
+		dst = append(dst, key[(code>>uint(i*5))&0x1f])
+	}
+	return dst, maxLat, minLat, maxLng, minLng
+}
+
+// quantize returns index of the cell among 2^n ones holding v and the cell edges,
+// v on an edge belongs to the lower cell as bisection does
+func quantize(v, min, max float64, n uint) (uint32, float64, float64) {
+	cells := uint32(1) << n
+	w := (max - min) / float64(cells)
+	idx := uint32(0)
+	if v > min {
+		if t := (v - min) / w; t < float64(cells) {
+			idx = uint32(t)
+		} else {
+			idx = cells - 1
+		}
+		// correct float rounding of the division against exact edges
+		for idx > 0 && min+float64(idx)*w >= v {
+			idx--
+		}
+		for idx < cells-1 && min+float64(idx+1)*w < v {
+			idx++
+		}
+	}
+	return idx, min + float64(idx)*w, min + float64(idx+1)*w
+}
+
+func appendEncodeBisect(bf []byte, latitude, longitude float64,
+	key []byte, precision int) ([]byte, float64, float64, float64, float64) {
 
 	minLat, maxLat, minLng, maxLng := MinLat, MaxLat, MinLng, MaxLng
-	bf := make([]byte, 0, precision)
 
 	resultLen, ch, byteCount, alter := 0, 0, 0, true
 	for resultLen < precision {
@@ -49,20 +115,45 @@ func encode(latitude, longitude float64,
 			ch, byteCount, resultLen = 0, 0, resultLen+1
 		}
 	}
-
-	return string(bf[:]), maxLat, minLat, maxLng, minLng
+	return bf, maxLat, minLat, maxLng, minLng
 }
 
-func decode(hashv string, key []byte, precision int) (float64, float64, float64, float64, float64, float64) {
-	hashb := []byte(hashv)
+func decode[T string | []byte](hashv T, lookup *[256]byte, precision int) (float64, float64, float64, float64, float64, float64) {
 	if precision <= 0 {
-		precision = len(hashb)
+		precision = len(hashv)
 	}
+	var minLat, maxLat, minLng, maxLng float64
+	if len(hashv) <= fastPrecision {
+		code := uint64(0)
+		for i := 0; i < len(hashv); i++ {
+			code = code<<5 | uint64(lookup[hashv[i]])
+		}
+		bits := uint(len(hashv) * 5)
+		latIdx, lngIdx := squash64(code), squash64(code>>1)
+		if bits%2 == 1 {
+			latIdx, lngIdx = squash64(code>>1), squash64(code)
+		}
+		minLat, maxLat = cellEdges(latIdx, MinLat, MaxLat, bits/2)
+		minLng, maxLng = cellEdges(lngIdx, MinLng, MaxLng, bits-bits/2)
+	} else {
+		maxLat, minLat, maxLng, minLng = decodeBisect(hashv, lookup)
+	}
+	lat, lng := (maxLat+minLat)/2, (maxLng+minLng)/2
+	return roundFloat64(lat, precision), roundFloat64(lng, precision), maxLat, minLat, maxLng, minLng
+}
+
+// cellEdges returns edges of cell idx among 2^n ones
+func cellEdges(idx uint32, min, max float64, n uint) (float64, float64) {
+	w := (max - min) / float64(uint32(1)<<n)
+	return min + float64(idx)*w, min + float64(idx+1)*w
+}
+
+func decodeBisect[T string | []byte](hashv T, lookup *[256]byte) (float64, float64, float64, float64) {
 	minLat, maxLat, minLng, maxLng := MinLat, MaxLat, MinLng, MaxLng
 	alter := true
-	for i := 0; i < len(hashb); i++ {
+	for i := 0; i < len(hashv); i++ {
 		byteCount := 0
-		v := bytes.IndexByte(key, hashb[i])
+		v := int(lookup[hashv[i]])
 		for byteCount <= ByteWidth {
 			b := 0
 			if v&Bits[byteCount] > 0 {
@@ -87,8 +178,7 @@ func decode(hashv string, key []byte, precision int) (float64, float64, float64,
 			byteCount++
 		}
 	}
-	lat, lng := (maxLat+minLat)/2, (maxLng+minLng)/2
-	return roundFloat64(lat, precision), roundFloat64(lng, precision), maxLat, minLat, maxLng, minLng
+	return maxLat, minLat, maxLng, minLng
 }
 
 func latErr(l int) float64 {
@@ -117,12 +207,20 @@ func NewGeoHash(key string) GeoCryptor {
 // for more detail, please check following link
 // https://en.wikipedia.org/wiki/Geohash
 type GeoHash struct {
-	key []byte
+	key    []byte
+	lookup [256]byte
 }
 
-// SetKey set hash key value
+// SetKey set hash key value and builds reverse lookup table of key,
+// characters out of key decode as all bits set, the first occurrence wins
 func (g *GeoHash) SetKey(key string) {
 	g.key = []byte(key)
+	for i := range g.lookup {
+		g.lookup[i] = 0x1f
+	}
+	for i := len(key) - 1; i >= 0; i-- {
+		g.lookup[key[i]] = byte(i & 0x1f)
+	}
 }
 
 // HashKey return hash key of this hasher
@@ -130,44 +228,109 @@ func (g *GeoHash) HashKey() string {
 	return string(g.key)
 }
 
-// Encode and return hash value only
+// MaxPrecision returns the longest hash decoded correctly, see MaxGeoHashPrecision
+func (g *GeoHash) MaxPrecision() int {
+	return MaxGeoHashPrecision
+}
+
+// Encode and return hash value only, empty beyond MaxGeoHashPrecision, see EncodeChecked
 func (g *GeoHash) Encode(latitude, longitude float64, precision int) string {
-	v, _, _, _, _ := encode(latitude, longitude, g.key, precision)
+	v, _ := g.EncodeChecked(latitude, longitude, precision)
 	return v
 }
 
-// Decode and return central lat, lng pair
+// EncodeChecked returns hash value or PrecisionError beyond MaxGeoHashPrecision
+func (g *GeoHash) EncodeChecked(latitude, longitude float64, precision int) (string, error) {
+	if err := checkLength(MaxGeoHashPrecision, precision); err != nil {
+		return "", err
+	}
+	v, _, _, _, _ := encode(latitude, longitude, g.key, precision)
+	return v, nil
+}
+
+// Decode and return central lat, lng pair, -999 beyond MaxGeoHashPrecision, see DecodeChecked
 func (g *GeoHash) Decode(value string, precision int) (float64, float64) {
-	lat, lng, _, _, _, _ := decode(value, g.key, precision)
+	lat, lng, err := g.DecodeChecked(value, precision)
+	if err != nil {
+		return -999.0, -999.0
+	}
 	return lat, lng
 }
 
+// DecodeChecked returns central lat, lng pair or PrecisionError
+// when value or precision exceeds MaxGeoHashPrecision
+func (g *GeoHash) DecodeChecked(value string, precision int) (float64, float64, error) {
+	if err := checkLength(MaxGeoHashPrecision, len(value), precision); err != nil {
+		return 0, 0, err
+	}
+	lat, lng, _, _, _, _ := decode(value, &g.lookup, precision)
+	return lat, lng, nil
+}
+
 // EncodeWithErr returns also estimate error in degree
 func (g *GeoHash) EncodeWithErr(latitude, longitude float64, precision int) (string, float64, float64) {
-	v, _, _, _, _ := encode(latitude, longitude, g.key, precision)
-	return v, latErr(precision), lngErr(precision)
+	return g.Encode(latitude, longitude, precision), latErr(precision), lngErr(precision)
 }
 
 // DecodeWithErr returns also estimate error
 func (g *GeoHash) DecodeWithErr(value string, precision int) (float64, float64, float64, float64) {
-	lat, lng, _, _, _, _ := decode(value, g.key, precision)
+	lat, lng := g.Decode(value, precision)
 	return lat, lng, latErr(precision), lngErr(precision)
 }
 
-// EncodeAsBox returns a location box
+// EncodeAsBox returns a location box, see EncodeBox for precision errors
 func (g *GeoHash) EncodeAsBox(latitude, longitude float64, precision int) BoundingBox {
+	lb, _ := g.EncodeBox(latitude, longitude, precision)
+	return &lb
+}
+
+// DecodeAsBox returns a location box, see DecodeBox for precision errors
+func (g *GeoHash) DecodeAsBox(value string, precision int) BoundingBox {
+	lb, _ := g.DecodeBox(value, precision)
+	return &lb
+}
+
+// AppendEncode appends hash to dst, nothing is allocated when dst has room
+// and nothing is appended beyond MaxGeoHashPrecision
+func (g *GeoHash) AppendEncode(dst []byte, latitude, longitude float64, precision int) []byte {
+	if checkLength(MaxGeoHashPrecision, precision) != nil {
+		return dst
+	}
+	dst, _, _, _, _ = appendEncode(dst, latitude, longitude, g.key, precision)
+	return dst
+}
+
+// DecodeBytes returns central lat, lng pair of hash in b as Decode of whole hash
+func (g *GeoHash) DecodeBytes(b []byte) (float64, float64) {
+	if checkLength(MaxGeoHashPrecision, len(b)) != nil {
+		return -999.0, -999.0
+	}
+	lat, lng, _, _, _, _ := decode(b, &g.lookup, 0)
+	return lat, lng
+}
+
+// EncodeBox returns location box as value, only the hash string is allocated,
+// zero box and PrecisionError beyond MaxGeoHashPrecision
+func (g *GeoHash) EncodeBox(latitude, longitude float64, precision int) (LocationBox, error) {
+	if err := checkLength(MaxGeoHashPrecision, precision); err != nil {
+		return LocationBox{}, err
+	}
 	v, maxlat, minlat, maxlng, minlng := encode(latitude, longitude, g.key, precision)
-	return &LocationBox{
+	return LocationBox{
 		MaxLat: maxlat, MinLat: minlat, MaxLng: maxlng, MinLng: minlng,
-		LatErr: latErr(precision), LngErr: lngErr(precision), Hash: v, Precision: precision}
+		LatErr: latErr(precision), LngErr: lngErr(precision), Hash: v, Precision: precision}, nil
 }
 
-// DecodeAsBox returns a location box
-func (g *GeoHash) DecodeAsBox(value string, precision int) BoundingBox {
-	_, _, maxlat, minlat, maxlng, minlng := decode(value, g.key, precision)
-	return &LocationBox{
+// DecodeBox returns location box as value without allocation,
+// zero box and PrecisionError when value or precision exceeds MaxGeoHashPrecision
+func (g *GeoHash) DecodeBox(value string, precision int) (LocationBox, error) {
+	if err := checkLength(MaxGeoHashPrecision, len(value), precision); err != nil {
+		return LocationBox{}, err
+	}
+	_, _, maxlat, minlat, maxlng, minlng := decode(value, &g.lookup, precision)
+	return LocationBox{
 		MaxLat: maxlat, MinLat: minlat, MaxLng: maxlng, MinLng: minlng,
-		LatErr: latErr(precision), LngErr: lngErr(precision), Hash: value, Precision: precision}
+		LatErr: latErr(precision), LngErr: lngErr(precision), Hash: value, Precision: precision}, nil
 }
 
 // Neighbors returns adjcent 8 neighbors
@@ -175,17 +338,18 @@ func (g *GeoHash) Neighbors(value string, precision int) []BoundingBox {
 	lb := g.DecodeAsBox(value, precision)
 	neighbor := func(lb *LocationBox, dlat, dlng int) *LocationBox {
 		latUnit, lngUnit := lb.LatErr*2*float64(dlat), lb.LngErr*2*float64(dlng)
-		r := &LocationBox{
+		r := LocationBox{
 			MaxLat: lb.MaxLat + latUnit, MinLat: lb.MinLat + latUnit,
 			MaxLng: lb.MaxLng + lngUnit, MinLng: lb.MinLng + lngUnit,
-			LatErr: lb.LatErr, LngErr: lb.LngErr, Precision: lb.Precision}
-		lat, lng, err := r.GetCenter()
-		if err != nil {
+			LatErr: lb.LatErr, LngErr: lb.LngErr, Precision: lb.Precision}.Normalize()
+		if err := r.validBox(); err != nil {
 			log.Printf("Erro while get neighbor <%v>\n", err)
 			return nil
 		}
+		// exact center, rounding may move it into another cell
+		lat, lng := r.Center()
 		r.Hash = g.Encode(lat, lng, precision)
-		return r
+		return &r
 	}
 	n := make([]BoundingBox, 0, 8)
 	for i := -1; i < 2; i++ {
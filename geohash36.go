@@ -17,53 +17,72 @@ var (
 
 func encode36(latitude, longitude float64, key []byte, precision int) (
 	hash string, maxLat, minLat, maxLng, minLng, unitLat, unitLng float64) {
-	b := make([]byte, 0, precision)
+	bp := bufPool.Get().(*[]byte)
+	b, maxLat, minLat, maxLng, minLng, unitLat, unitLng := appendEncode36((*bp)[:0], latitude, longitude, key, precision)
+	hash = string(b)
+	*bp = b
+	bufPool.Put(bp)
+	return hash, maxLat, minLat, maxLng, minLng, unitLat, unitLng
+}
+
+// appendEncode36 appends hash to b and returns extended buffer with cell edges and units
+func appendEncode36(b []byte, latitude, longitude float64, key []byte, precision int) (
+	[]byte, float64, float64, float64, float64, float64, float64) {
+	var maxLat, minLat, maxLng, minLng, unitLat, unitLng float64
 	unitLng, maxLng, minLng = initUnitLng, MaxLng, MinLng
 	unitLat, maxLat, minLat = initUnitLat, MaxLat, MinLat
 	for i := 0; i < precision; i++ {
 		// since max search time is 12 (6 for each),
 		// just iterate it instead of binary search
 		clat, clng := 0, 0
-		for (maxLat - unitLat) > latitude {
+		// we divide unit by 6 which contains 3 leads to repeating decimal,
+		// stop at the last row and column so float error never leaves the cell
+		for clat < 5 && (maxLat-unitLat) > latitude {
 			clat++
 			maxLat -= unitLat
-			// float number present limitation &&
-			// we divide unit by 6 which contains 3 leads to repeating decimal
-			if clat == 6 && maxLat-latitude < 10e-9 {
-				clat--
-			}
 		}
-		for (minLng + unitLng) < longitude {
+		for clng < 5 && (minLng+unitLng) < longitude {
 			clng++
 			minLng += unitLng
-			if clng == 6 && maxLat-latitude < 10e-9 {
-				clat--
-			}
 		}
-		// update square
-		minLat, maxLng = maxLat-unitLat, minLng+unitLng
+		// update square, the last row and column keep edges of the parent
+		// so cells nest exactly despite rounding
+		if clat < 5 {
+			minLat = maxLat - unitLat
+		}
+		if clng < 5 {
+			maxLng = minLng + unitLng
+		}
 		unitLat, unitLng = unitLat/6, unitLng/6
 		b = append(b, key[clat*6+clng])
 	}
 	_, _ = (minLat+maxLat)/2, (minLng+maxLng)/2
-	return string(b[:]), maxLat, minLat, maxLng, minLng, roundFloat64(unitLat, precision), roundFloat64(unitLng, precision)
+	return b, maxLat, minLat, maxLng, minLng, roundFloat64(unitLat, precision), roundFloat64(unitLng, precision)
 }
 
-func decode36(hashv string, key []byte, precision int) (
+func decode36[T string | []byte](hashv T, key []byte, precision int) (
 	lat, lng, maxLat, minLat, maxLng, minLng, uLat, uLng float64) {
-	hashb := []byte(hashv)
 	if precision <= 0 {
-		precision = len(hashb)
+		precision = len(hashv)
 	}
 	uLat, maxLat, minLat = initUnitLat, MaxLat, MinLat
 	uLng, maxLng, minLng = initUnitLng, MaxLng, MinLng
-	for _, v := range hashb {
-		i := bytes.IndexByte(B36, v)
+	for j := 0; j < len(hashv); j++ {
+		i := bytes.IndexByte(key, hashv[j])
+		// step edges unit by unit as the encoder does, so both round alike
 		row, col := i/6, i%6
-		maxLat = maxLat - float64(row)*uLat
-		minLat = maxLat - uLat
-		minLng = minLng + float64(col)*uLng
-		maxLng = minLng + uLng
+		for r := 0; r < row; r++ {
+			maxLat -= uLat
+		}
+		for c := 0; c < col; c++ {
+			minLng += uLng
+		}
+		if row < 5 {
+			minLat = maxLat - uLat
+		}
+		if col < 5 {
+			maxLng = minLng + uLng
+		}
 		uLat, uLng = uLat/6, uLng/6
 	}
 	return roundFloat64((maxLat+minLat)/2, precision), roundFloat64((maxLng+minLng)/2, precision),
@@ -100,44 +119,111 @@ func (g *GeoHash36) HashKey() string {
 	return string(g.key)
 }
 
-// Encode and return hash value only
+// MaxPrecision returns the longest hash encoded correctly, see MaxGeoHash36Precision
+func (g *GeoHash36) MaxPrecision() int {
+	return MaxGeoHash36Precision
+}
+
+// Encode and return hash value only, empty beyond MaxGeoHash36Precision, see EncodeChecked
 func (g *GeoHash36) Encode(latitude, longitude float64, precision int) string {
-	v, _, _, _, _, _, _ := encode36(latitude, longitude, g.key, precision)
+	v, _ := g.EncodeChecked(latitude, longitude, precision)
 	return v
 }
 
-// Decode and return central lat, lng pair
+// EncodeChecked returns hash value or PrecisionError beyond MaxGeoHash36Precision
+func (g *GeoHash36) EncodeChecked(latitude, longitude float64, precision int) (string, error) {
+	if err := checkLength(MaxGeoHash36Precision, precision); err != nil {
+		return "", err
+	}
+	v, _, _, _, _, _, _ := encode36(latitude, longitude, g.key, precision)
+	return v, nil
+}
+
+// Decode and return central lat, lng pair, -999 beyond MaxGeoHash36Precision, see DecodeChecked
 func (g *GeoHash36) Decode(value string, precision int) (float64, float64) {
-	lat, lng, _, _, _, _, _, _ := decode36(value, g.key, precision)
+	lat, lng, err := g.DecodeChecked(value, precision)
+	if err != nil {
+		return -999.0, -999.0
+	}
 	return lat, lng
 }
 
+// DecodeChecked returns central lat, lng pair or PrecisionError
+// when value or precision exceeds MaxGeoHash36Precision
+func (g *GeoHash36) DecodeChecked(value string, precision int) (float64, float64, error) {
+	if err := checkLength(MaxGeoHash36Precision, len(value), precision); err != nil {
+		return 0, 0, err
+	}
+	lat, lng, _, _, _, _, _, _ := decode36(value, g.key, precision)
+	return lat, lng, nil
+}
+
 // EncodeWithErr returns also estimate error in degree
 func (g *GeoHash36) EncodeWithErr(latitude, longitude float64, precision int) (string, float64, float64) {
-	v, _, _, _, _, latErr, lngErr := encode36(latitude, longitude, g.key, precision)
-	return v, latErr * 6, lngErr * 6
+	lb, _ := g.EncodeBox(latitude, longitude, precision)
+	return lb.Hash, lb.LatErr, lb.LngErr
 }
 
 // DecodeWithErr returns also estimate error
 func (g *GeoHash36) DecodeWithErr(value string, precision int) (float64, float64, float64, float64) {
-	lat, lng, _, _, _, _, latErr, lngErr := decode36(value, g.key, precision)
-	return lat, lng, latErr * 6, lngErr * 6
+	lat, lng := g.Decode(value, precision)
+	lb, _ := g.DecodeBox(value, precision)
+	return lat, lng, lb.LatErr, lb.LngErr
 }
 
-// EncodeAsBox returns a location box
+// EncodeAsBox returns a location box, see EncodeBox for precision errors
 func (g *GeoHash36) EncodeAsBox(latitude, longitude float64, precision int) BoundingBox {
+	lb, _ := g.EncodeBox(latitude, longitude, precision)
+	return &lb
+}
+
+// DecodeAsBox returns a location box, see DecodeBox for precision errors
+func (g *GeoHash36) DecodeAsBox(value string, precision int) BoundingBox {
+	lb, _ := g.DecodeBox(value, precision)
+	return &lb
+}
+
+// AppendEncode appends hash to dst, nothing is allocated when dst has room
+// and nothing is appended beyond MaxGeoHash36Precision
+func (g *GeoHash36) AppendEncode(dst []byte, latitude, longitude float64, precision int) []byte {
+	if checkLength(MaxGeoHash36Precision, precision) != nil {
+		return dst
+	}
+	dst, _, _, _, _, _, _ = appendEncode36(dst, latitude, longitude, g.key, precision)
+	return dst
+}
+
+// DecodeBytes returns central lat, lng pair of hash in b as Decode of whole hash
+func (g *GeoHash36) DecodeBytes(b []byte) (float64, float64) {
+	if checkLength(MaxGeoHash36Precision, len(b)) != nil {
+		return -999.0, -999.0
+	}
+	lat, lng, _, _, _, _, _, _ := decode36(b, g.key, 0)
+	return lat, lng
+}
+
+// EncodeBox returns location box as value, only the hash string is allocated,
+// zero box and PrecisionError beyond MaxGeoHash36Precision
+func (g *GeoHash36) EncodeBox(latitude, longitude float64, precision int) (LocationBox, error) {
+	if err := checkLength(MaxGeoHash36Precision, precision); err != nil {
+		return LocationBox{}, err
+	}
 	v, maxlat, minlat, maxlng, minlng, latErr, lngErr := encode36(latitude, longitude, g.key, precision)
-	return &LocationBox{
+	return LocationBox{
 		MaxLat: maxlat, MinLat: minlat, MaxLng: maxlng, MinLng: minlng,
-		LatErr: latErr * 6, LngErr: lngErr * 6, Hash: v, Precision: precision}
+		LatErr: latErr * 6, LngErr: lngErr * 6, Hash: v, Precision: precision}, nil
 }
 
-// DecodeAsBox returns a location box
-func (g *GeoHash36) DecodeAsBox(value string, precision int) BoundingBox {
+// DecodeBox returns location box as value without allocation,
+// zero box and PrecisionError when value or precision exceeds MaxGeoHash36Precision
+func (g *GeoHash36) DecodeBox(value string, precision int) (LocationBox, error) {
+	if err := checkLength(MaxGeoHash36Precision, len(value), precision); err != nil {
+		return LocationBox{}, err
+	}
 	_, _, maxlat, minlat, maxlng, minlng, latErr, lngErr := decode36(value, g.key, precision)
-	return &LocationBox{
+	return LocationBox{
 		MaxLat: maxlat, MinLat: minlat, MaxLng: maxlng, MinLng: minlng,
-		LatErr: latErr * 6, LngErr: lngErr * 6, Hash: value, Precision: precision}
+		LatErr: latErr * 6, LngErr: lngErr * 6, Hash: value, Precision: precision}, nil
 }
 
 // Neighbors returns adjcent 8 neighbors
@@ -145,17 +231,18 @@ func (g *GeoHash36) Neighbors(value string, precision int) []BoundingBox {
 	lb := g.DecodeAsBox(value, precision)
 	neighbor := func(lb *LocationBox, dlat, dlng int) *LocationBox {
 		latUnit, lngUnit := (lb.MaxLat-lb.MinLat)*float64(dlat), (lb.MaxLng-lb.MinLng)*float64(dlng)
-		r := &LocationBox{
+		r := LocationBox{
 			MaxLat: lb.MaxLat + latUnit, MinLat: lb.MinLat + latUnit,
 			MaxLng: lb.MaxLng + lngUnit, MinLng: lb.MinLng + lngUnit,
-			LatErr: lb.LatErr, LngErr: lb.LngErr}
-		lat, lng, err := r.GetCenter()
-		if err != nil {
+			LatErr: lb.LatErr, LngErr: lb.LngErr}.Normalize()
+		if err := r.validBox(); err != nil {
 			fmt.Printf("Neighbor error: %v\n", err)
 			return nil
 		}
+		// exact center, rounding may move it into another cell
+		lat, lng := r.Center()
 		r.Hash = g.Encode(lat, lng, precision)
-		return r
+		return &r
 	}
 	n := make([]BoundingBox, 0, 8)
 	for i := -1; i < 2; i++ {
@@ -2,8 +2,11 @@ package geohash
 
 import (
 	"fmt"
+	"math"
+	"math/rand"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -126,3 +129,102 @@ func BenchmarkNeighbors36(b *testing.B) {
 		cryptor.Neighbors("H2RXqLHNG6", 8)
 	}
 }
+
+func TestEncode36WorldEdges(t *testing.T) {
+	cryptor := NewDefaultGeoHash36()
+	// south and east world edges fall in the last row and column of every level
+	tr := []struct {
+		Lat, Lng float64
+		Char     byte
+	}{
+		{MaxLat, MinLng, DefaultB36Str[0]},
+		{MaxLat, MaxLng, DefaultB36Str[5]},
+		{MinLat, MinLng, DefaultB36Str[30]},
+		{MinLat, MaxLng, DefaultB36Str[35]},
+		{0, MaxLng, 0},
+		{MinLat, 0, 0},
+	}
+	for _, v := range tr {
+		for p := 1; p <= MaxGeoHash36Precision; p++ {
+			h := cryptor.Encode(v.Lat, v.Lng, p)
+			lb, _ := cryptor.(*GeoHash36).DecodeBox(h, p)
+			if len(h) != p || v.Char != 0 && h != strings.Repeat(string(v.Char), p) ||
+				lb.MinLat > v.Lat || lb.MaxLat < v.Lat || lb.MinLng > v.Lng || lb.MaxLng < v.Lng {
+				_, file, line, _ := runtime.Caller(0)
+				fmt.Printf("%s:%d: (%v, %v) at %d\n\n\tgot: %#v %+v\n\n", filepath.Base(file), line, v.Lat, v.Lng, p, h, lb)
+				t.FailNow()
+			}
+		}
+	}
+}
+
+func TestRoundTrip36(t *testing.T) {
+	cryptor := NewDefaultGeoHash36().(*GeoHash36)
+	rand.Seed(42)
+	for i := 0; i < 100000; i++ {
+		lat, lng := rand.Float64()*180-90, rand.Float64()*360-180
+		// points on grid lines of the first three levels
+		if i%3 == 0 {
+			lat = MinLat + float64(rand.Intn(217))*(MaxLat-MinLat)/216
+		}
+		if i%5 == 0 {
+			lng = MinLng + float64(rand.Intn(217))*(MaxLng-MinLng)/216
+		}
+		for _, p := range []int{1, 8, 12, MaxGeoHash36Precision} {
+			h := cryptor.Encode(lat, lng, p)
+			lb, err := cryptor.DecodeBox(h, p)
+			if err != nil || lat < lb.MinLat || lat > lb.MaxLat || lng < lb.MinLng || lng > lb.MaxLng {
+				_, file, line, _ := runtime.Caller(0)
+				fmt.Printf("%s:%d: (%v, %v) at %d\n\n\tgot: %#v %+v %v\n\n", filepath.Base(file), line, lat, lng, p, h, lb, err)
+				t.FailNow()
+			}
+		}
+	}
+}
+
+func TestAppendEncode36(t *testing.T) {
+	cryptor := NewDefaultGeoHash36().(*GeoHash36)
+	buf := cryptor.AppendEncode([]byte("h:"), 25.03297033, 121.56542031, 10)
+	exp := "h:" + cryptor.Encode(25.03297033, 121.56542031, 10)
+	if string(buf) != exp {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, string(buf))
+		t.FailNow()
+	}
+
+	lat, lng := cryptor.DecodeBytes([]byte("H2RXqLHNG6"))
+	explat, explng := cryptor.Decode("H2RXqLHNG6", 0)
+	if lat != explat || lng != explng {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: (%v, %v)\n\n\tgot: (%v, %v)\n\n", filepath.Base(file), line, explat, explng, lat, lng)
+		t.FailNow()
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = cryptor.AppendEncode(buf[:0], 25.03297033, 121.56542031, 10)
+		cryptor.DecodeBytes(buf)
+		cryptor.DecodeBox("H2RXqLHNG6", 10)
+	})
+	if allocs != 0 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: 0 allocs\n\n\tgot: %v\n\n", filepath.Base(file), line, allocs)
+		t.FailNow()
+	}
+}
+
+func TestNeighbors36(t *testing.T) {
+	cryptor := NewDefaultGeoHash36()
+	lb := cryptor.DecodeAsBox("H2RXqLHNG6", 10).(*LocationBox)
+	h, w := lb.MaxLat-lb.MinLat, lb.MaxLng-lb.MinLng
+	for i, b := range cryptor.Neighbors("H2RXqLHNG6", 10) {
+		hash, _ := b.Geohash()
+		nb := cryptor.DecodeAsBox(hash, 10).(*LocationBox)
+		dlat, dlng := (nb.MinLat-lb.MinLat)/h, (nb.MinLng-lb.MinLng)/w
+		if math.Abs(math.Abs(dlat)-1) > 1e-6 && math.Abs(dlat) > 1e-6 ||
+			math.Abs(math.Abs(dlng)-1) > 1e-6 && math.Abs(dlng) > 1e-6 || math.Abs(dlat)+math.Abs(dlng) < 1e-6 {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: neighbor %d %s\n\n\tgot offset: (%v, %v)\n\n", filepath.Base(file), line, i, hash, dlat, dlng)
+			t.FailNow()
+		}
+	}
+}
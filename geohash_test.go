@@ -2,6 +2,7 @@ package geohash
 
 import (
 	"fmt"
+	"math/rand"
 	"path/filepath"
 	"reflect"
 	"runtime"
@@ -220,6 +221,79 @@ func TestDecodeError(t *testing.T) {
 	}
 }
 
+func TestEncodeEdges(t *testing.T) {
+	cryptor := NewDefaultGeoHash()
+
+	// points on grid lines belong to the lower cell
+	tr := []struct {
+		Lat, Lng  float64
+		Precision int
+		Exp       string
+	}{
+		{0, 0, 1, "7"},
+		{45, 45, 2, "sz"},
+		{90, 180, 3, "zzz"},
+		{-90, -180, 3, "000"},
+		{100, 200, 2, "zz"},
+	}
+	for _, v := range tr {
+		if got := cryptor.Encode(v.Lat, v.Lng, v.Precision); got != v.Exp {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, v, v.Exp, got)
+			t.FailNow()
+		}
+	}
+
+	// hashes beyond integer path extend shorter ones
+	long := cryptor.Encode(12.04512315, 118.20385763, 14)
+	short := cryptor.DecodeAsBox(long[:12], 12).(*LocationBox)
+	deep := cryptor.DecodeAsBox(long, 14).(*LocationBox)
+	if long[:12] != cryptor.Encode(12.04512315, 118.20385763, 12) ||
+		deep.MinLat < short.MinLat || deep.MaxLat > short.MaxLat || deep.MinLng < short.MinLng || deep.MaxLng > short.MaxLng {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v in %#v\n\n", filepath.Base(file), line, deep, short)
+		t.FailNow()
+	}
+}
+
+func TestFastPathBisectEquivalence(t *testing.T) {
+	g := NewDefaultGeoHash().(*GeoHash)
+	rand.Seed(42)
+	check := func(lat, lng float64, precision int) {
+		fast, maxLat, minLat, maxLng, minLng := appendEncode(nil, lat, lng, g.key, precision)
+		slow, smaxLat, sminLat, smaxLng, sminLng := appendEncodeBisect(nil, lat, lng, g.key, precision)
+		if string(fast) != string(slow) || maxLat != smaxLat || minLat != sminLat || maxLng != smaxLng || minLng != sminLng {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: encode (%v, %v) at %d\n\n\texp: %s %v\n\n\tgot: %s %v\n\n", filepath.Base(file), line, lat, lng, precision,
+				slow, []float64{sminLat, smaxLat, sminLng, smaxLng}, fast, []float64{minLat, maxLat, minLng, maxLng})
+			t.FailNow()
+		}
+		_, _, maxLat, minLat, maxLng, minLng = decode(fast, &g.lookup, 0)
+		smaxLat, sminLat, smaxLng, sminLng = decodeBisect(fast, &g.lookup)
+		if maxLat != smaxLat || minLat != sminLat || maxLng != smaxLng || minLng != sminLng {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: decode %s\n\n\texp: %v\n\n\tgot: %v\n\n", filepath.Base(file), line, fast,
+				[]float64{sminLat, smaxLat, sminLng, smaxLng}, []float64{minLat, maxLat, minLng, maxLng})
+			t.FailNow()
+		}
+	}
+	for p := 1; p <= fastPrecision; p++ {
+		for i := 0; i < 5000; i++ {
+			check(rand.Float64()*180-90, rand.Float64()*360-180, p)
+		}
+		// points on grid lines of this and coarser levels, world edges included
+		bits := uint(p * 5)
+		latCells, lngCells := 1<<(bits/2), 1<<(bits-bits/2)
+		for i := 0; i < 2000; i++ {
+			lat := MinLat + float64(rand.Intn(latCells+1))*(MaxLat-MinLat)/float64(latCells)
+			lng := MinLng + float64(rand.Intn(lngCells+1))*(MaxLng-MinLng)/float64(lngCells)
+			check(lat, lng, p)
+			check(lat, rand.Float64()*360-180, p)
+			check(rand.Float64()*180-90, lng, p)
+		}
+	}
+}
+
 func TestNeighbors(t *testing.T) {
 	cryptor := NewDefaultGeoHash()
 	got, exp := []string{}, []string{}
@@ -299,3 +373,50 @@ func BenchmarkNeighbors(b *testing.B) {
 		cryptor.Neighbors("7ztuee", 6)
 	}
 }
+
+func TestAppendEncode(t *testing.T) {
+	cryptor := NewDefaultGeoHash().(*GeoHash)
+	buf := make([]byte, 0, 32)
+	buf = cryptor.AppendEncode(buf, 12.04512315, 118.20385763, 9)
+	buf = append(buf, ',')
+	buf = cryptor.AppendEncode(buf, 12.04512315, 118.20385763, 14)
+	exp := cryptor.Encode(12.04512315, 118.20385763, 9) + "," + cryptor.Encode(12.04512315, 118.20385763, 14)
+	if string(buf) != exp {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, string(buf))
+		t.FailNow()
+	}
+
+	lat, lng := cryptor.DecodeBytes([]byte("wdhh9b9rv"))
+	explat, explng := cryptor.Decode("wdhh9b9rv", 0)
+	if lat != explat || lng != explng {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: (%v, %v)\n\n\tgot: (%v, %v)\n\n", filepath.Base(file), line, explat, explng, lat, lng)
+		t.FailNow()
+	}
+	if lb, _ := cryptor.DecodeBox("wdhh9b9rv", 9); lb != *cryptor.DecodeAsBox("wdhh9b9rv", 9).(*LocationBox) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %#v\n\n", filepath.Base(file), line, lb)
+		t.FailNow()
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = cryptor.AppendEncode(buf[:0], 12.04512315, 118.20385763, 12)
+		cryptor.DecodeBytes(buf)
+		cryptor.DecodeBox("wdhh9b9rv", 9)
+	})
+	if allocs != 0 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: 0 allocs\n\n\tgot: %v\n\n", filepath.Base(file), line, allocs)
+		t.FailNow()
+	}
+}
+
+func BenchmarkAppendEncode(b *testing.B) {
+	b.ReportAllocs()
+	cryptor := NewDefaultGeoHash().(*GeoHash)
+	buf := make([]byte, 0, 12)
+	for i := 0; i < b.N; i++ {
+		buf = cryptor.AppendEncode(buf[:0], 12.04512315, 118.20385763, 12)
+	}
+}
@@ -0,0 +1,229 @@
+package geohash
+
+import (
+	"math"
+	"math/big"
+)
+
+// NewDefaultGeoHashBig return a high precision geohash cryptor with default key
+func NewDefaultGeoHashBig() GeoCryptor {
+	return NewGeoHashBig(DefaultB32Str)
+}
+
+// NewGeoHashBig return a high precision geohash cryptor with given key
+func NewGeoHashBig(key string) GeoCryptor {
+	g := &GeoHashBig{}
+	g.SetKey(key)
+	return g
+}
+
+// GeoHashBig computes the same hashes as GeoHash with exact rational arithmetic,
+// so hashes of any length are right at the cost of speed. Float64 results are
+// the nearest values of exact ones, use EncodeRat and DecodeRat
+// when hashes are finer than float64 coordinates
+type GeoHashBig struct {
+	key    []byte
+	lookup [256]byte
+}
+
+// SetKey set hash key value
+func (g *GeoHashBig) SetKey(key string) {
+	gh := GeoHash{}
+	gh.SetKey(key)
+	g.key, g.lookup = gh.key, gh.lookup
+}
+
+// HashKey return hash key of this hasher
+func (g *GeoHashBig) HashKey() string {
+	return string(g.key)
+}
+
+// MaxPrecision returns the longest hash of this cryptor, it is not limited
+func (g *GeoHashBig) MaxPrecision() int {
+	return math.MaxInt32
+}
+
+// Encode and return hash value only
+func (g *GeoHashBig) Encode(latitude, longitude float64, precision int) string {
+	return g.EncodeRat(ratOf(latitude, MaxLat), ratOf(longitude, MaxLng), precision)
+}
+
+// Decode and return central lat, lng pair rounded to precision decimals as GeoHash,
+// length of value if precision <= 0. Centers of hashes longer than float64 digits
+// are the nearest values of exact ones
+func (g *GeoHashBig) Decode(value string, precision int) (float64, float64) {
+	if precision <= 0 {
+		precision = len(value)
+	}
+	lat, lng := g.DecodeRat(value)
+	flat, _ := lat.Float64()
+	flng, _ := lng.Float64()
+	return roundDecimal(flat, precision), roundDecimal(flng, precision)
+}
+
+// EncodeWithErr returns also half size of cell in degree
+func (g *GeoHashBig) EncodeWithErr(latitude, longitude float64, precision int) (string, float64, float64) {
+	lb := g.EncodeAsBox(latitude, longitude, precision).(*LocationBox)
+	return lb.Hash, lb.LatErr, lb.LngErr
+}
+
+// DecodeWithErr returns also half size of cell in degree
+func (g *GeoHashBig) DecodeWithErr(value string, precision int) (float64, float64, float64, float64) {
+	lat, lng := g.Decode(value, precision)
+	lb := g.DecodeAsBox(value, precision).(*LocationBox)
+	return lat, lng, lb.LatErr, lb.LngErr
+}
+
+// EncodeAsBox returns a location box
+func (g *GeoHashBig) EncodeAsBox(latitude, longitude float64, precision int) BoundingBox {
+	value := g.Encode(latitude, longitude, precision)
+	return g.box(value, precision)
+}
+
+// DecodeAsBox returns a location box
+func (g *GeoHashBig) DecodeAsBox(value string, precision int) BoundingBox {
+	return g.box(value, precision)
+}
+
+// Neighbors returns adjcent 8 neighbors, cells beyond poles are nil
+// and cells beyond 180th meridian wrap around
+func (g *GeoHashBig) Neighbors(value string, precision int) []BoundingBox {
+	latIdx, lngIdx, latBits, lngBits := g.indexes(value)
+	one := big.NewInt(1)
+	latCells, lngCells := new(big.Int).Lsh(one, latBits), new(big.Int).Lsh(one, lngBits)
+	n := make([]BoundingBox, 0, 8)
+	for i := -1; i < 2; i++ {
+		for j := -1; j < 2; j++ {
+			if i == 0 && j == 0 {
+				continue
+			}
+			lat := new(big.Int).Add(latIdx, big.NewInt(int64(i)))
+			if lat.Sign() < 0 || lat.Cmp(latCells) >= 0 {
+				n = append(n, (*LocationBox)(nil))
+				continue
+			}
+			lng := new(big.Int).Add(lngIdx, big.NewInt(int64(j)))
+			lng.Mod(lng, lngCells)
+			n = append(n, g.box(g.hash(lat, lng, len(value)), precision))
+		}
+	}
+	return n
+}
+
+// EncodeRat encodes exact coordinates, a point on a cell edge belongs to the lower cell as GeoHash
+func (g *GeoHashBig) EncodeRat(latitude, longitude *big.Rat, precision int) string {
+	if precision < 0 {
+		precision = 0
+	}
+	bits := uint(precision * 5)
+	return g.hash(bigIndex(latitude, MinLat, MaxLat, bits/2), bigIndex(longitude, MinLng, MaxLng, bits-bits/2), precision)
+}
+
+// DecodeRat returns exact center of hash
+func (g *GeoHashBig) DecodeRat(value string) (lat, lng *big.Rat) {
+	minLat, maxLat, minLng, maxLng := g.edges(value)
+	lat = new(big.Rat).Add(minLat, maxLat)
+	lng = new(big.Rat).Add(minLng, maxLng)
+	half := big.NewRat(1, 2)
+	return lat.Mul(lat, half), lng.Mul(lng, half)
+}
+
+func (g *GeoHashBig) box(value string, precision int) *LocationBox {
+	minLat, maxLat, minLng, maxLng := g.edges(value)
+	latErr, lngErr := new(big.Rat).Sub(maxLat, minLat), new(big.Rat).Sub(maxLng, minLng)
+	half := big.NewRat(1, 2)
+	f := func(r *big.Rat) float64 {
+		v, _ := r.Float64()
+		return v
+	}
+	return &LocationBox{
+		MaxLat: f(maxLat), MinLat: f(minLat), MaxLng: f(maxLng), MinLng: f(minLng),
+		LatErr: f(latErr.Mul(latErr, half)), LngErr: f(lngErr.Mul(lngErr, half)),
+		Hash: value, Precision: precision}
+}
+
+// edges returns exact edges of hash cell
+func (g *GeoHashBig) edges(value string) (minLat, maxLat, minLng, maxLng *big.Rat) {
+	latIdx, lngIdx, latBits, lngBits := g.indexes(value)
+	minLat, maxLat = bigEdges(latIdx, MinLat, MaxLat, latBits)
+	minLng, maxLng = bigEdges(lngIdx, MinLng, MaxLng, lngBits)
+	return minLat, maxLat, minLng, maxLng
+}
+
+// indexes splits hash bits into latitude and longitude cell indexes
+func (g *GeoHashBig) indexes(value string) (latIdx, lngIdx *big.Int, latBits, lngBits uint) {
+	bits := uint(len(value) * 5)
+	latBits, lngBits = bits/2, bits-bits/2
+	latIdx, lngIdx = new(big.Int), new(big.Int)
+	for i := uint(0); i < bits; i++ {
+		b := uint(g.lookup[value[i/5]]>>(4-i%5)) & 1
+		if i%2 == 0 {
+			lngIdx.SetBit(lngIdx, int(lngBits-1-i/2), b)
+		} else {
+			latIdx.SetBit(latIdx, int(latBits-1-i/2), b)
+		}
+	}
+	return latIdx, lngIdx, latBits, lngBits
+}
+
+// hash interleaves cell indexes into hash of precision characters
+func (g *GeoHashBig) hash(latIdx, lngIdx *big.Int, precision int) string {
+	bits := uint(precision * 5)
+	latBits, lngBits := bits/2, bits-bits/2
+	b := make([]byte, precision)
+	for i := uint(0); i < bits; i++ {
+		var bit uint
+		if i%2 == 0 {
+			bit = lngIdx.Bit(int(lngBits - 1 - i/2))
+		} else {
+			bit = latIdx.Bit(int(latBits - 1 - i/2))
+		}
+		b[i/5] |= byte(bit << (4 - i%5))
+	}
+	for i := range b {
+		b[i] = g.key[b[i]]
+	}
+	return string(b)
+}
+
+// ratOf converts coordinate to rational, infinities become twice the limit
+// and NaN the lower limit so they fall into the same cells as GeoHash
+func ratOf(v, limit float64) *big.Rat {
+	switch {
+	case math.IsNaN(v):
+		v = -limit
+	case math.IsInf(v, 0):
+		v = math.Copysign(2*limit, v)
+	}
+	return new(big.Rat).SetFloat64(v)
+}
+
+// bigIndex returns index of the cell among 2^n ones holding v,
+// v on an edge belongs to the lower cell
+func bigIndex(v *big.Rat, min, max float64, n uint) *big.Int {
+	cells := new(big.Int).Lsh(big.NewInt(1), n)
+	// t = (v - min) * 2^n / (max - min), index is ceil(t) - 1
+	t := new(big.Rat).Sub(v, new(big.Rat).SetFloat64(min))
+	t.Mul(t, new(big.Rat).SetInt(cells))
+	t.Quo(t, new(big.Rat).SetFloat64(max-min))
+	if t.Sign() <= 0 {
+		return new(big.Int)
+	}
+	idx, rem := new(big.Int).QuoRem(t.Num(), t.Denom(), new(big.Int))
+	if rem.Sign() == 0 {
+		idx.Sub(idx, big.NewInt(1))
+	}
+	if idx.Cmp(cells) >= 0 {
+		idx.Sub(cells, big.NewInt(1))
+	}
+	return idx
+}
+
+// bigEdges returns exact edges of cell idx among 2^n ones
+func bigEdges(idx *big.Int, min, max float64, n uint) (*big.Rat, *big.Rat) {
+	w := new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Lsh(big.NewInt(1), n))
+	w.Mul(w, new(big.Rat).SetFloat64(max-min))
+	lo := new(big.Rat).Mul(new(big.Rat).SetInt(idx), w)
+	lo.Add(lo, new(big.Rat).SetFloat64(min))
+	return lo, new(big.Rat).Add(lo, w)
+}
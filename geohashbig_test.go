@@ -0,0 +1,172 @@
+package geohash
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestGeoHashBigMatchesGeoHash(t *testing.T) {
+	gh, big := NewDefaultGeoHash(), NewDefaultGeoHashBig()
+	r := rand.New(rand.NewSource(1))
+	points := [][2]float64{{0, 0}, {90, 180}, {-90, -180}, {45, 45}, {12.04512315, 118.20385763}}
+	for i := 0; i < 200; i++ {
+		points = append(points, [2]float64{r.Float64()*180 - 90, r.Float64()*360 - 180})
+	}
+	for _, p := range points {
+		for precision := 1; precision <= MaxGeoHashPrecision; precision++ {
+			exp := gh.EncodeAsBox(p[0], p[1], precision).(*LocationBox)
+			got := big.EncodeAsBox(p[0], p[1], precision).(*LocationBox)
+			if exp.Hash != got.Hash || exp.MinLat != got.MinLat || exp.MaxLat != got.MaxLat ||
+				exp.MinLng != got.MinLng || exp.MaxLng != got.MaxLng {
+				_, file, line, _ := runtime.Caller(0)
+				fmt.Printf("%s:%d: %v %d\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, p, precision, exp, got)
+				t.FailNow()
+			}
+		}
+	}
+}
+
+func TestGeoHashBigRoundTrip(t *testing.T) {
+	g := NewDefaultGeoHashBig().(*GeoHashBig)
+
+	// float64 center stays in cell of 20 characters
+	h := g.Encode(12.04512315, 118.20385763, 20)
+	if lat, lng := g.Decode(h, 0); g.Encode(lat, lng, 20) != h || h[:12] != NewDefaultGeoHash().Encode(12.04512315, 118.20385763, 12) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %v %v %v\n\n", filepath.Base(file), line, h, lat, lng)
+		t.FailNow()
+	}
+
+	// exact coordinates round trip at any length
+	h = "wdhh9b9rvzzzzzzzzzzzzzzzzzzzzzzzzz0"
+	lat, lng := g.DecodeRat(h)
+	if got := g.EncodeRat(lat, lng, len(h)); got != h {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %v\n\n\tgot: %v\n\n", filepath.Base(file), line, h, got)
+		t.FailNow()
+	}
+	if got := g.EncodeRat(big.NewRat(0, 1), big.NewRat(0, 1), 2); got != "7z" {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: 7z\n\n\tgot: %v\n\n", filepath.Base(file), line, got)
+		t.FailNow()
+	}
+}
+
+func TestGeoHashBigNeighbors(t *testing.T) {
+	g := NewDefaultGeoHashBig()
+	hashes := func(boxes []BoundingBox) []string {
+		n := []string{}
+		for _, b := range boxes {
+			if lb, ok := b.(*LocationBox); !ok || lb == nil {
+				n = append(n, "")
+				continue
+			}
+			h, _ := b.Geohash()
+			n = append(n, h)
+		}
+		return n
+	}
+
+	exp := []string{"7ztue6", "7ztued", "7ztuef", "7ztue7", "7ztueg", "7ztuek", "7ztues", "7ztueu"}
+	if got := hashes(g.Neighbors("7ztuee", 6)); !reflect.DeepEqual(exp, got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+	// north-east corner cell wraps to the west and has no northern neighbors
+	exp = []string{"w", "x", "8", "y", "b", "", "", ""}
+	if got := hashes(g.Neighbors("z", 1)); !reflect.DeepEqual(exp, got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+	// missing neighbors are typed nil boxes as GeoHash has them
+	for _, b := range g.Neighbors("z", 1)[5:] {
+		if lb, ok := b.(*LocationBox); !ok || lb != nil {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\n\texp: (*LocationBox)(nil)\n\n\tgot: %#v\n\n", filepath.Base(file), line, b)
+			t.FailNow()
+		}
+	}
+}
+
+func TestCheckPrecision(t *testing.T) {
+	tr := []struct {
+		C         GeoCryptor
+		Precision int
+		Err       error
+	}{
+		{NewDefaultGeoHash(), MaxGeoHashPrecision, nil},
+		{NewDefaultGeoHash(), 20, PrecisionError{Precision: 20, Max: MaxGeoHashPrecision}},
+		{NewDefaultGeoHash36(), 17, PrecisionError{Precision: 17, Max: MaxGeoHash36Precision}},
+		{NewDefaultGeoHashBig(), 100, nil},
+	}
+	for _, v := range tr {
+		if err := CheckPrecision(v.C, v.Precision); err != v.Err {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %d\n\n\texp: %v\n\n\tgot: %v\n\n", filepath.Base(file), line, v.Precision, v.Err, err)
+			t.FailNow()
+		}
+	}
+
+	if _, err := NewHash(SchemeGeoHash36, "bdrdC26BqHbdrdC26BqH"); !errors.As(err, &PrecisionError{}) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp precision error of long geohash36\n\n\tgot: %v\n\n", filepath.Base(file), line, err)
+		t.FailNow()
+	}
+	h, _ := NewHash(SchemeGeoHash, "wdhh9b9rvzzzzzzzzzzz")
+	lb := h.Box().(*LocationBox)
+	if lb.MaxLat <= lb.MinLat || lb.MaxLng <= lb.MinLng || lb.Precision != 20 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %#v\n\n", filepath.Base(file), line, lb)
+		t.FailNow()
+	}
+}
+
+func TestStandardPrecisionErrors(t *testing.T) {
+	long := "wdhh9b9rvzzzzzzzzzzz"
+	for _, c := range []GeoCryptor{NewDefaultGeoHash(), NewDefaultGeoHash36()} {
+		checked := c.(interface {
+			EncodeChecked(latitude, longitude float64, precision int) (string, error)
+			DecodeChecked(value string, precision int) (float64, float64, error)
+			EncodeBox(latitude, longitude float64, precision int) (LocationBox, error)
+			DecodeBox(value string, precision int) (LocationBox, error)
+		})
+		exp := PrecisionError{Precision: 20, Max: 16}
+		_, errEnc := checked.EncodeChecked(12.04512315, 118.20385763, 20)
+		_, _, errDec := checked.DecodeChecked(long, 0)
+		_, errEncBox := checked.EncodeBox(12.04512315, 118.20385763, 20)
+		_, errDecBox := checked.DecodeBox(long, 20)
+		if errEnc != exp || errDec != exp || errEncBox != exp || errDecBox != exp {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\n\texp: %v\n\n\tgot: %v, %v, %v, %v\n\n", filepath.Base(file), line, exp, errEnc, errDec, errEncBox, errDecBox)
+			t.FailNow()
+		}
+		lat, lng := c.Decode(long, 0)
+		if h := c.Encode(12.04512315, 118.20385763, 20); h != "" || lat != -999 || lng != -999 {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\n\tgot: %q, (%v, %v)\n\n", filepath.Base(file), line, h, lat, lng)
+			t.FailNow()
+		}
+		if h, err := checked.EncodeChecked(12.04512315, 118.20385763, 16); err != nil || len(h) != 16 {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\n\tgot: %q, %v\n\n", filepath.Base(file), line, h, err)
+			t.FailNow()
+		}
+	}
+
+	// precision rounds decimals as GeoHash does
+	lat, lng := NewDefaultGeoHashBig().Decode("wdhh9b9rv", 3)
+	explat, explng := NewDefaultGeoHash().Decode("wdhh9b9rv", 3)
+	if lat != explat || lng != explng {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: (%v, %v)\n\n\tgot: (%v, %v)\n\n", filepath.Base(file), line, explat, explng, lat, lng)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,114 @@
+package geohash
+
+import "encoding/json"
+
+// GeoJSON object types
+const (
+	TypeFeature           = "Feature"
+	TypeFeatureCollection = "FeatureCollection"
+	TypePolygon           = "Polygon"
+	TypeMultiPolygon      = "MultiPolygon"
+)
+
+// Polygon is a GeoJSON polygon geometry, coordinates are [lng, lat] pairs
+type Polygon struct {
+	Type        string         `json:"type"`
+	Coordinates [][][2]float64 `json:"coordinates"`
+}
+
+// MultiPolygon is a GeoJSON multipolygon geometry, coordinates are [lng, lat] pairs
+type MultiPolygon struct {
+	Type        string           `json:"type"`
+	Coordinates [][][][2]float64 `json:"coordinates"`
+}
+
+// Feature is a GeoJSON feature of a single cell,
+// geometry is Polygon or MultiPolygon, see LocationBox.Geometry
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   interface{}            `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// FeatureCollection is a GeoJSON feature collection of cells
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Polygon returns box as a closed counterclockwise ring starting at south-west corner,
+// a box crossing the antimeridian is no single polygon, see Geometry
+func (lb LocationBox) Polygon() Polygon {
+	return Polygon{
+		Type: TypePolygon,
+		Coordinates: [][][2]float64{{
+			{lb.MinLng, lb.MinLat}, {lb.MaxLng, lb.MinLat},
+			{lb.MaxLng, lb.MaxLat}, {lb.MinLng, lb.MaxLat},
+			{lb.MinLng, lb.MinLat},
+		}},
+	}
+}
+
+// Geometry returns box as Polygon, or as MultiPolygon of its parts on either side
+// when box crosses the antimeridian as RFC 7946 section 3.1.9 requires
+func (lb LocationBox) Geometry() interface{} {
+	if !lb.CrossesAntimeridian() {
+		return lb.Polygon()
+	}
+	mp := MultiPolygon{Type: TypeMultiPolygon}
+	for _, part := range lb.split() {
+		mp.Coordinates = append(mp.Coordinates, part.Polygon().Coordinates)
+	}
+	return mp
+}
+
+// Feature returns box as GeoJSON feature with hash, precision and errors in properties
+func (lb LocationBox) Feature() Feature {
+	return Feature{
+		Type:     TypeFeature,
+		Geometry: lb.Geometry(),
+		Properties: map[string]interface{}{
+			"hash":      lb.Hash,
+			"precision": lb.Precision,
+			"latErr":    lb.LatErr,
+			"lngErr":    lb.LngErr,
+		},
+	}
+}
+
+// GeoJSON returns encoded GeoJSON feature of box
+func (lb LocationBox) GeoJSON() ([]byte, error) {
+	return json.Marshal(lb.Feature())
+}
+
+// NewFeatureCollection collects boxes into feature collection,
+// nil boxes, e.g. neighbors out of range, are skipped
+func NewFeatureCollection(boxes []BoundingBox) FeatureCollection {
+	fc := FeatureCollection{Type: TypeFeatureCollection, Features: make([]Feature, 0, len(boxes))}
+	for _, b := range boxes {
+		switch lb := b.(type) {
+		case *LocationBox:
+			if lb != nil {
+				fc.Features = append(fc.Features, lb.Feature())
+			}
+		case LocationBox:
+			fc.Features = append(fc.Features, lb.Feature())
+		}
+	}
+	return fc
+}
+
+// BoxesGeoJSON returns encoded GeoJSON feature collection of boxes
+func BoxesGeoJSON(boxes []BoundingBox) ([]byte, error) {
+	return json.Marshal(NewFeatureCollection(boxes))
+}
+
+// HashesGeoJSON decodes hashes with cryptor and
+// returns encoded GeoJSON feature collection of them
+func HashesGeoJSON(c GeoCryptor, hashes []string) ([]byte, error) {
+	boxes := make([]BoundingBox, 0, len(hashes))
+	for _, h := range hashes {
+		boxes = append(boxes, c.DecodeAsBox(h, len(h)))
+	}
+	return BoxesGeoJSON(boxes)
+}
@@ -0,0 +1,58 @@
+package geohash
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestGeoJSON(t *testing.T) {
+	cryptor := NewDefaultGeoHash()
+
+	got, err := cryptor.DecodeAsBox("ez", 2).(*LocationBox).GeoJSON()
+	exp := `{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[-11.25,39.375],[0,39.375],[0,45],[-11.25,45],[-11.25,39.375]]]},"properties":{"hash":"ez","latErr":2.81,"lngErr":5.63,"precision":2}}`
+	if err != nil || string(got) != exp {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %s\n\n\tgot: %s\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+}
+
+func TestNeighborsGeoJSON(t *testing.T) {
+	cryptor := NewDefaultGeoHash()
+
+	fc := NewFeatureCollection(cryptor.Neighbors("gz", 2))
+	got, exp := []string{}, []string{"gw", "gy", "un", "gx", "up"}
+	for _, f := range fc.Features {
+		got = append(got, f.Properties["hash"].(string))
+	}
+	if fc.Type != TypeFeatureCollection || !reflect.DeepEqual(exp, got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+
+	b, err := HashesGeoJSON(cryptor, []string{"7"})
+	expJSON := `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[-45,-45],[0,-45],[0,0],[-45,0],[-45,-45]]]},"properties":{"hash":"7","latErr":22.5,"lngErr":22.5,"precision":1}}]}`
+	if err != nil || string(b) != expJSON {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %s\n\n\tgot: %s\n\n", filepath.Base(file), line, expJSON, b)
+		t.FailNow()
+	}
+}
+
+func TestAntimeridianGeoJSON(t *testing.T) {
+	pacific := LocationBox{MinLat: -10, MinLng: 170, MaxLat: 10, MaxLng: -170, LatErr: 10, LngErr: 10}
+	got, err := pacific.GeoJSON()
+	exp := `{"type":"Feature","geometry":{"type":"MultiPolygon","coordinates":[` +
+		`[[[170,-10],[180,-10],[180,10],[170,10],[170,-10]]],` +
+		`[[[-180,-10],[-170,-10],[-170,10],[-180,10],[-180,-10]]]]},` +
+		`"properties":{"hash":"","latErr":10,"lngErr":10,"precision":0}}`
+	if err != nil || string(got) != exp {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %s\n\n\tgot: %s\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+}
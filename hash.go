@@ -0,0 +1,221 @@
+package geohash
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Scheme identifies hash algorithm of a Hash value
+type Scheme int
+
+// supported schemes
+const (
+	SchemeGeoHash Scheme = iota
+	SchemeGeoHash36
+)
+
+var schemeNames = []string{"geohash", "geohash36"}
+
+func (s Scheme) String() string {
+	if s < 0 || int(s) >= len(schemeNames) {
+		return fmt.Sprintf("Scheme(%d)", int(s))
+	}
+	return schemeNames[s]
+}
+
+// ParseScheme returns scheme of given name, e.g. "geohash" or "geohash36"
+func ParseScheme(name string) (Scheme, error) {
+	for i, n := range schemeNames {
+		if strings.EqualFold(n, name) {
+			return Scheme(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown scheme %q", name)
+}
+
+// Key returns default hash key of scheme
+func (s Scheme) Key() string {
+	if s == SchemeGeoHash36 {
+		return DefaultB36Str
+	}
+	return DefaultB32Str
+}
+
+// NewCryptor returns default cryptor of scheme
+func (s Scheme) NewCryptor() GeoCryptor {
+	return s.NewCryptorWithKey(s.Key())
+}
+
+// NewCryptorWithKey returns cryptor of scheme with given key
+func (s Scheme) NewCryptorWithKey(key string) GeoCryptor {
+	if s == SchemeGeoHash36 {
+		return NewGeoHash36(key)
+	}
+	return NewGeoHash(key)
+}
+
+// Hash is a validated hash value with its scheme,
+// text form is bare value for geohash and "geohash36:<value>" for geohash-36.
+// Zero value is an empty hash which is stored as SQL NULL
+type Hash struct {
+	scheme Scheme
+	value  string
+}
+
+// NewHash returns validated hash
+func NewHash(scheme Scheme, value string) (Hash, error) {
+	h := Hash{scheme: scheme, value: value}
+	if err := h.Validate(); err != nil {
+		return Hash{}, err
+	}
+	return h, nil
+}
+
+// ParseHash parses text form of hash
+func ParseHash(text string) (Hash, error) {
+	h := Hash{}
+	err := h.UnmarshalText([]byte(text))
+	return h, err
+}
+
+// Scheme returns scheme of hash
+func (h Hash) Scheme() Scheme {
+	return h.scheme
+}
+
+// Precision returns length of hash
+func (h Hash) Precision() int {
+	return len(h.value)
+}
+
+// IsZero reports whether hash is empty
+func (h Hash) IsZero() bool {
+	return h.value == ""
+}
+
+// Validate checks scheme and every character of hash value
+func (h Hash) Validate() error {
+	if h.scheme != SchemeGeoHash && h.scheme != SchemeGeoHash36 {
+		return HashError{Value: h.value, Msg: "unknown scheme " + h.scheme.String()}
+	}
+	if h.value == "" {
+		return HashError{Value: h.value, Msg: "empty hash"}
+	}
+	if h.scheme == SchemeGeoHash36 && len(h.value) > MaxGeoHash36Precision {
+		pe := PrecisionError{Precision: len(h.value), Max: MaxGeoHash36Precision}
+		return HashError{Value: h.value, Msg: pe.Error(), Err: pe}
+	}
+	key := h.scheme.Key()
+	for i := 0; i < len(h.value); i++ {
+		if strings.IndexByte(key, h.value[i]) < 0 {
+			return HashError{Value: h.value, Msg: fmt.Sprintf("invalid %v character %q at %d", h.scheme, h.value[i], i)}
+		}
+	}
+	return nil
+}
+
+// Box decodes hash with default cryptor of its scheme,
+// geohash values beyond MaxGeoHashPrecision are decoded by GeoHashBig
+func (h Hash) Box() BoundingBox {
+	if h.scheme == SchemeGeoHash && h.Precision() > MaxGeoHashPrecision {
+		return NewDefaultGeoHashBig().DecodeAsBox(h.value, h.Precision())
+	}
+	return h.scheme.NewCryptor().DecodeAsBox(h.value, h.Precision())
+}
+
+func (h Hash) String() string {
+	if h.scheme == SchemeGeoHash || h.value == "" {
+		return h.value
+	}
+	return h.scheme.String() + ":" + h.value
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler,
+// empty text results zero hash
+func (h *Hash) UnmarshalText(text []byte) error {
+	s := string(text)
+	if s == "" {
+		*h = Hash{}
+		return nil
+	}
+	r := Hash{scheme: SchemeGeoHash, value: s}
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		scheme, err := ParseScheme(s[:i])
+		if err != nil {
+			return HashError{Value: s, Msg: err.Error()}
+		}
+		r = Hash{scheme: scheme, value: s[i+1:]}
+	}
+	if err := r.Validate(); err != nil {
+		return err
+	}
+	*h = r
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (h Hash) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler, null results zero hash
+func (h *Hash) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*h = Hash{}
+		return nil
+	}
+	s := ""
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return h.UnmarshalText([]byte(s))
+}
+
+// Scan implements sql.Scanner
+func (h *Hash) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*h = Hash{}
+		return nil
+	case string:
+		return h.UnmarshalText([]byte(v))
+	case []byte:
+		return h.UnmarshalText(v)
+	}
+	return HashError{Value: fmt.Sprint(src), Msg: fmt.Sprintf("cannot scan %T", src)}
+}
+
+// Value implements driver.Valuer, zero hash is stored as NULL
+func (h Hash) Value() (driver.Value, error) {
+	if h.IsZero() {
+		return nil, nil
+	}
+	if err := h.Validate(); err != nil {
+		return nil, err
+	}
+	return h.String(), nil
+}
+
+// HashError is returned for malformed hash value,
+// Err is the underlying error such as PrecisionError if any
+type HashError struct {
+	Value string
+	Msg   string
+	Err   error
+}
+
+func (he HashError) Error() string {
+	return fmt.Sprintf("Wrong hash %q: %v", he.Value, he.Msg)
+}
+
+// Unwrap returns underlying error
+func (he HashError) Unwrap() error {
+	return he.Err
+}
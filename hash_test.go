@@ -0,0 +1,98 @@
+package geohash
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+var (
+	_ encoding.TextMarshaler   = Hash{}
+	_ encoding.TextUnmarshaler = &Hash{}
+	_ json.Marshaler           = Hash{}
+	_ json.Unmarshaler         = &Hash{}
+	_ sql.Scanner              = &Hash{}
+	_ driver.Valuer            = Hash{}
+)
+
+func TestHashJSON(t *testing.T) {
+	v := struct {
+		A Hash `json:"a"`
+		B Hash `json:"b"`
+		C Hash `json:"c"`
+	}{}
+	v.A, _ = NewHash(SchemeGeoHash, "wdhh9b9rv")
+	v.B, _ = NewHash(SchemeGeoHash36, "bdrdC26")
+
+	b, err := json.Marshal(v)
+	exp := `{"a":"wdhh9b9rv","b":"geohash36:bdrdC26","c":""}`
+	if err != nil || string(b) != exp {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %s\n\n\tgot: %s, %v\n\n", filepath.Base(file), line, exp, b, err)
+		t.FailNow()
+	}
+
+	v.A, v.B = Hash{}, Hash{}
+	err = json.Unmarshal(b, &v)
+	if err != nil || v.A.String() != "wdhh9b9rv" || v.B.Scheme() != SchemeGeoHash36 || v.B.Precision() != 7 || !v.C.IsZero() {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, v, err)
+		t.FailNow()
+	}
+
+	for _, in := range []string{`{"a":"wdhha"}`, `{"b":"geohash36:bdrda"}`, `{"a":"geohash37:wd"}`, `{"a":1}`} {
+		if err := json.Unmarshal([]byte(in), &v); err == nil {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\n\texp error for %s\n\n", filepath.Base(file), line, in)
+			t.FailNow()
+		}
+	}
+}
+
+func TestHashSQL(t *testing.T) {
+	h := Hash{}
+	if err := h.Scan([]byte("geohash36:H2RXqLHNG6")); err != nil || h.String() != "geohash36:H2RXqLHNG6" {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, h, err)
+		t.FailNow()
+	}
+
+	v, err := h.Value()
+	if err != nil || v != "geohash36:H2RXqLHNG6" {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, v, err)
+		t.FailNow()
+	}
+
+	if err := h.Scan(nil); err != nil || !h.IsZero() {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, h, err)
+		t.FailNow()
+	}
+	if v, err := h.Value(); err != nil || v != nil {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, v, err)
+		t.FailNow()
+	}
+
+	if err := h.Scan(42); err == nil {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp error\n\n", filepath.Base(file), line)
+		t.FailNow()
+	}
+}
+
+func TestLocationBoxJSON(t *testing.T) {
+	b, err := json.Marshal(NewDefaultGeoHash().DecodeAsBox("7", 1))
+	exp := `{"maxLat":0,"minLat":-45,"maxLng":0,"minLng":-45,"latErr":22.5,"lngErr":22.5,"hash":"7","precision":1}`
+	if err != nil || string(b) != exp {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %s\n\n\tgot: %s, %v\n\n", filepath.Base(file), line, exp, b, err)
+		t.FailNow()
+	}
+}
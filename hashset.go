@@ -0,0 +1,269 @@
+package geohash
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HashSet is a region made of cells of one cryptor, stored as a prefix tree.
+// A cell covers all of its children, so hashes of any precision can be mixed,
+// and cells whose children are all present are merged into their parent.
+// Sets combined by set algebra must use cryptors of the same key, set algebra
+// panics on sets of different keys since their cells do not line up
+type HashSet struct {
+	c   GeoCryptor
+	key string
+	// root is nil for an empty set
+	root *hashNode
+}
+
+// hashNode is a cell of the tree, a full cell has no children.
+// Nodes are never modified once built, so sets share them and
+// operations copy every node they change
+type hashNode struct {
+	full     bool
+	children []*hashNode
+}
+
+// NewHashSet returns empty set of cells of cryptor c
+func NewHashSet(c GeoCryptor) *HashSet {
+	return &HashSet{c: c, key: c.HashKey()}
+}
+
+// Add puts cells of hashes into set, empty hash is the whole world.
+// Hashes with characters out of key are rejected with HashError, hashes longer than
+// cryptor supports with PrecisionError, and none is added
+func (hs *HashSet) Add(hashes ...string) error {
+	if err := hs.validate(hashes); err != nil {
+		return err
+	}
+	for _, h := range hashes {
+		hs.root = hs.add(hs.root, h)
+	}
+	return nil
+}
+
+// Remove takes cells of hashes out of set, removing part of a cell splits it
+func (hs *HashSet) Remove(hashes ...string) error {
+	if err := hs.validate(hashes); err != nil {
+		return err
+	}
+	for _, h := range hashes {
+		if hs.root == nil {
+			break
+		}
+		hs.root = hs.difference(hs.root, hs.add(nil, h))
+	}
+	return nil
+}
+
+func (hs *HashSet) validate(hashes []string) error {
+	for _, h := range hashes {
+		if err := CheckPrecision(hs.c, len(h)); err != nil {
+			return err
+		}
+		for i := 0; i < len(h); i++ {
+			if strings.IndexByte(hs.key, h[i]) < 0 {
+				return HashError{Value: h, Msg: fmt.Sprintf("invalid character %q at %d", h[i], i)}
+			}
+		}
+	}
+	return nil
+}
+
+// add returns copy of n with cell of hash h added
+func (hs *HashSet) add(n *hashNode, h string) *hashNode {
+	if n != nil && n.full {
+		return n
+	}
+	if h == "" {
+		return &hashNode{full: true}
+	}
+	c := &hashNode{children: make([]*hashNode, len(hs.key))}
+	if n != nil {
+		copy(c.children, n.children)
+	}
+	i := strings.IndexByte(hs.key, h[0])
+	c.children[i] = hs.add(c.children[i], h[1:])
+	return hs.compact(c)
+}
+
+// compact merges node whose children are all full and drops node without children
+func (hs *HashSet) compact(n *hashNode) *hashNode {
+	if n == nil || n.full {
+		return n
+	}
+	full, empty := true, true
+	for _, c := range n.children {
+		full = full && c != nil && c.full
+		empty = empty && c == nil
+	}
+	switch {
+	case empty:
+		return nil
+	case full:
+		return &hashNode{full: true}
+	}
+	return n
+}
+
+// Contains reports whether the whole cell of hash h is in set,
+// a set holding "9q8" contains "9q8yy" but a set holding only "9q8yy" does not contain "9q8"
+func (hs *HashSet) Contains(h string) bool {
+	n := hs.root
+	for i := 0; n != nil; i++ {
+		if n.full {
+			return true
+		}
+		if i == len(h) {
+			return false
+		}
+		k := strings.IndexByte(hs.key, h[i])
+		if k < 0 {
+			return false
+		}
+		n = n.children[k]
+	}
+	return false
+}
+
+// Intersects reports whether any part of the cell of hash h is in set
+func (hs *HashSet) Intersects(h string) bool {
+	n := hs.root
+	for i := 0; n != nil; i++ {
+		if n.full || i == len(h) {
+			return true
+		}
+		k := strings.IndexByte(hs.key, h[i])
+		if k < 0 {
+			return false
+		}
+		n = n.children[k]
+	}
+	return false
+}
+
+// Len returns number of cells in set after merging
+func (hs *HashSet) Len() int {
+	count := 0
+	hs.walk(func(string) { count++ })
+	return count
+}
+
+// Hashes returns cells of set in key order
+func (hs *HashSet) Hashes() []string {
+	n := []string{}
+	hs.walk(func(h string) { n = append(n, h) })
+	return n
+}
+
+// Area returns total surface of set in square meters, see LocationBox.Area
+func (hs *HashSet) Area() float64 {
+	area := 0.0
+	hs.walk(func(h string) {
+		area += hs.c.DecodeAsBox(h, len(h)).(*LocationBox).Area()
+	})
+	return area
+}
+
+// walk calls fn with hash of every full cell in key order
+func (hs *HashSet) walk(fn func(h string)) {
+	var visit func(n *hashNode, prefix []byte)
+	visit = func(n *hashNode, prefix []byte) {
+		if n == nil {
+			return
+		}
+		if n.full {
+			fn(string(prefix))
+			return
+		}
+		for i, c := range n.children {
+			visit(c, append(prefix, hs.key[i]))
+		}
+	}
+	visit(hs.root, make([]byte, 0, 16))
+}
+
+// Union returns cells in either set
+func (hs *HashSet) Union(o *HashSet) *HashSet {
+	hs.mustMatch(o)
+	return &HashSet{c: hs.c, key: hs.key, root: hs.union(hs.root, o.root)}
+}
+
+// Intersection returns cells in both sets
+func (hs *HashSet) Intersection(o *HashSet) *HashSet {
+	hs.mustMatch(o)
+	return &HashSet{c: hs.c, key: hs.key, root: hs.intersection(hs.root, o.root)}
+}
+
+// Difference returns cells in set but not in o
+func (hs *HashSet) Difference(o *HashSet) *HashSet {
+	hs.mustMatch(o)
+	return &HashSet{c: hs.c, key: hs.key, root: hs.difference(hs.root, o.root)}
+}
+
+// SymmetricDifference returns cells in exactly one of the sets
+func (hs *HashSet) SymmetricDifference(o *HashSet) *HashSet {
+	hs.mustMatch(o)
+	return &HashSet{c: hs.c, key: hs.key,
+		root: hs.union(hs.difference(hs.root, o.root), hs.difference(o.root, hs.root))}
+}
+
+// mustMatch panics when o holds cells of another key
+func (hs *HashSet) mustMatch(o *HashSet) {
+	if hs.key != o.key {
+		panic(fmt.Sprintf("geohash: set algebra on HashSets of different keys %q and %q", hs.key, o.key))
+	}
+}
+
+func (hs *HashSet) union(a, b *hashNode) *hashNode {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.full:
+		return a
+	case b.full:
+		return b
+	}
+	n := &hashNode{children: make([]*hashNode, len(hs.key))}
+	for i := range n.children {
+		n.children[i] = hs.union(a.children[i], b.children[i])
+	}
+	return hs.compact(n)
+}
+
+func (hs *HashSet) intersection(a, b *hashNode) *hashNode {
+	switch {
+	case a == nil || b == nil:
+		return nil
+	case a.full:
+		return b
+	case b.full:
+		return a
+	}
+	n := &hashNode{children: make([]*hashNode, len(hs.key))}
+	for i := range n.children {
+		n.children[i] = hs.intersection(a.children[i], b.children[i])
+	}
+	return hs.compact(n)
+}
+
+func (hs *HashSet) difference(a, b *hashNode) *hashNode {
+	switch {
+	case a == nil || b == nil:
+		return a
+	case b.full:
+		return nil
+	}
+	n := &hashNode{children: make([]*hashNode, len(hs.key))}
+	for i := range n.children {
+		if a.full {
+			n.children[i] = hs.difference(a, b.children[i])
+		} else {
+			n.children[i] = hs.difference(a.children[i], b.children[i])
+		}
+	}
+	return hs.compact(n)
+}
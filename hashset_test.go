@@ -0,0 +1,150 @@
+package geohash
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func newTestSet(hashes ...string) *HashSet {
+	hs := NewHashSet(NewDefaultGeoHash())
+	if err := hs.Add(hashes...); err != nil {
+		panic(err)
+	}
+	return hs
+}
+
+func TestHashSetContains(t *testing.T) {
+	hs := newTestSet("9q8", "dr5r")
+	tr := []struct {
+		Hash                string
+		Contains, Intersect bool
+	}{
+		{"9q8", true, true},
+		{"9q8yy", true, true},
+		{"9q", false, true},
+		{"", false, true},
+		{"9r", false, false},
+		{"dr5", false, true},
+		{"dr5ru", true, true},
+		{"dr5q", false, false},
+	}
+	for _, v := range tr {
+		if c, i := hs.Contains(v.Hash), hs.Intersects(v.Hash); c != v.Contains || i != v.Intersect {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %q\n\n\texp: %v, %v\n\n\tgot: %v, %v\n\n", filepath.Base(file), line, v.Hash, v.Contains, v.Intersect, c, i)
+			t.FailNow()
+		}
+	}
+
+	if err := hs.Add("9q8", "9qa"); err == nil || hs.Contains("9qb") {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d: invalid hash accepted\n\n", filepath.Base(file), line)
+		t.FailNow()
+	}
+}
+
+func TestHashSetMerge(t *testing.T) {
+	hs := newTestSet(Children(NewDefaultGeoHash(), "9q")...)
+	if got, exp := hs.Hashes(), []string{"9q"}; !reflect.DeepEqual(got, exp) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+
+	hs.Remove("9q8yy")
+	if hs.Len() != 93 || hs.Contains("9q8yy") || !hs.Contains("9q8yz") || !hs.Contains("9q9") || hs.Contains("9q8") {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %v\n\n", filepath.Base(file), line, hs.Hashes())
+		t.FailNow()
+	}
+	hs.Add("9q8yy")
+	if got, exp := hs.Hashes(), []string{"9q"}; !reflect.DeepEqual(got, exp) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+}
+
+func TestHashSetAlgebra(t *testing.T) {
+	a, b := newTestSet("9q8", "9r"), newTestSet("9q8y", "9q9", "9r2")
+	tr := []struct {
+		Got   *HashSet
+		Len   int
+		Cells []string
+	}{
+		{a.Union(b), 3, []string{"9q8", "9q9", "9r"}},
+		{a.Intersection(b), 2, []string{"9q8y", "9r2"}},
+		{b.Intersection(a), 2, []string{"9q8y", "9r2"}},
+		{a.Difference(b), 62, nil},
+		{b.Difference(a), 1, []string{"9q9"}},
+		{a.SymmetricDifference(b), 63, nil},
+		{a.Difference(a), 0, []string{}},
+	}
+	for _, v := range tr {
+		got := v.Got.Hashes()
+		if v.Got.Len() != v.Len || v.Cells != nil && !reflect.DeepEqual(got, v.Cells) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\n\texp: %v %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, v.Len, v.Cells, got)
+			t.FailNow()
+		}
+	}
+
+	// sets share nodes, changing one leaves the others alone
+	u := a.Union(b)
+	a.Add("u")
+	a.Remove("9r")
+	if u.Contains("u") || !u.Contains("9r") || !a.Contains("u") {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %v, %v\n\n", filepath.Base(file), line, u.Hashes(), a.Hashes())
+		t.FailNow()
+	}
+}
+
+func TestHashSetArea(t *testing.T) {
+	world := 4 * math.Pi * EarthRadius * EarthRadius
+	if a := newTestSet("").Area(); math.Abs(a-world) > world*1e-12 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %v\n\n\tgot: %v\n\n", filepath.Base(file), line, world, a)
+		t.FailNow()
+	}
+
+	whole := newTestSet("9q8")
+	part := whole.Difference(newTestSet("9q8yy"))
+	exp := whole.Area()
+	if a := part.Area() + newTestSet("9q8yy").Area(); math.Abs(a-exp) > exp*1e-9 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %v\n\n\tgot: %v\n\n", filepath.Base(file), line, exp, a)
+		t.FailNow()
+	}
+}
+
+func TestHashSetKeyMismatch(t *testing.T) {
+	a, b := newTestSet("9q8"), NewHashSet(NewDefaultGeoHash36())
+	b.Add("9q8")
+	for _, op := range []func(*HashSet) *HashSet{a.Union, a.Intersection, a.Difference, a.SymmetricDifference} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					_, file, line, _ := runtime.Caller(0)
+					fmt.Printf("%s:%d:\n\n\texp panic on different keys\n\n", filepath.Base(file), line)
+					t.FailNow()
+				}
+			}()
+			op(b)
+		}()
+	}
+}
+
+func TestHashSetPrecision(t *testing.T) {
+	hs := newTestSet("9q8")
+	if err := hs.Add("9q8yy", "wdhh9b9rvzzzzzzzzzzz"); !errors.As(err, &PrecisionError{}) || !hs.Contains("9q8yy0") || hs.Len() != 1 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp precision error\n\n\tgot: %v, %v\n\n", filepath.Base(file), line, err, hs.Hashes())
+		t.FailNow()
+	}
+}
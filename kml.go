@@ -0,0 +1,155 @@
+package geohash
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// KMLNamespace is the xml namespace of KML 2.2 documents
+const KMLNamespace = "http://www.opengis.net/kml/2.2"
+
+// KMLOptions controls labels, fill colors and grouping of exported cells
+type KMLOptions struct {
+	// Name of the document
+	Name string
+	// Values holds per-cell value keyed by hash used to pick fill color,
+	// cells without value use DefaultColor
+	Values map[string]float64
+	// Color maps a value to KML aabbggrr color,
+	// by default values are ramped from blue (min) to red (max)
+	Color func(value, min, max float64) string
+	// DefaultColor is used for cells without value, "7fffffff" if empty
+	DefaultColor string
+	// GroupByPrecision puts cells into folders by hash length
+	GroupByPrecision bool
+}
+
+type kmlDoc struct {
+	XMLName  xml.Name    `xml:"kml"`
+	XMLNS    string      `xml:"xmlns,attr"`
+	Document kmlDocument `xml:"Document"`
+}
+
+type kmlDocument struct {
+	Name       string         `xml:"name,omitempty"`
+	Folders    []kmlFolder    `xml:"Folder,omitempty"`
+	Placemarks []kmlPlacemark `xml:"Placemark,omitempty"`
+}
+
+type kmlFolder struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name        string `xml:"name"`
+	Description string `xml:"description,omitempty"`
+	Style       struct {
+		LineColor string `xml:"LineStyle>color"`
+		PolyColor string `xml:"PolyStyle>color"`
+	} `xml:"Style"`
+	// Polygon is nil when box crosses the antimeridian,
+	// MultiGeometry holds its parts on either side
+	Polygon       *kmlPolygon  `xml:"Polygon,omitempty"`
+	MultiGeometry []kmlPolygon `xml:"MultiGeometry>Polygon,omitempty"`
+}
+
+type kmlPolygon struct {
+	Coordinates string `xml:"outerBoundaryIs>LinearRing>coordinates"`
+}
+
+// BoxesKML returns KML document of boxes, nil boxes are skipped
+func BoxesKML(boxes []BoundingBox, opt KMLOptions) ([]byte, error) {
+	lbs := locationBoxes(boxes)
+	color := opt.Color
+	if color == nil {
+		color = rampColor
+	}
+	defColor := opt.DefaultColor
+	if defColor == "" {
+		defColor = "7fffffff"
+	}
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range opt.Values {
+		min, max = minFloat64(min, v), maxFloat64(max, v)
+	}
+
+	doc := kmlDoc{XMLNS: KMLNamespace, Document: kmlDocument{Name: opt.Name}}
+	folders := map[int]*kmlFolder{}
+	for _, lb := range lbs {
+		pm := kmlPlacemark{Name: lb.Hash}
+		if parts := lb.split(); len(parts) == 1 {
+			pm.Polygon = &kmlPolygon{Coordinates: kmlRing(lb)}
+		} else {
+			for _, part := range parts {
+				pm.MultiGeometry = append(pm.MultiGeometry, kmlPolygon{Coordinates: kmlRing(part)})
+			}
+		}
+		pm.Style.PolyColor = defColor
+		if v, ok := opt.Values[lb.Hash]; ok {
+			pm.Style.PolyColor = color(v, min, max)
+			pm.Description = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		pm.Style.LineColor = pm.Style.PolyColor
+		if len(pm.Style.LineColor) == 8 {
+			pm.Style.LineColor = "ff" + pm.Style.LineColor[2:]
+		}
+
+		if !opt.GroupByPrecision {
+			doc.Document.Placemarks = append(doc.Document.Placemarks, pm)
+			continue
+		}
+		p := len(lb.Hash)
+		if folders[p] == nil {
+			folders[p] = &kmlFolder{Name: fmt.Sprintf("precision %d", p)}
+		}
+		folders[p].Placemarks = append(folders[p].Placemarks, pm)
+	}
+
+	keys := make([]int, 0, len(folders))
+	for k := range folders {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	for _, k := range keys {
+		doc.Document.Folders = append(doc.Document.Folders, *folders[k])
+	}
+
+	b, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), b...), nil
+}
+
+// HashesKML decodes hashes with cryptor and returns KML document of them
+func HashesKML(c GeoCryptor, hashes []string, opt KMLOptions) ([]byte, error) {
+	boxes := make([]BoundingBox, 0, len(hashes))
+	for _, h := range hashes {
+		boxes = append(boxes, c.DecodeAsBox(h, len(h)))
+	}
+	return BoxesKML(boxes, opt)
+}
+
+func kmlRing(lb LocationBox) string {
+	pts := lb.Polygon().Coordinates[0]
+	s := make([]string, 0, len(pts))
+	for _, p := range pts {
+		s = append(s, formatWKTFloat(p[0])+","+formatWKTFloat(p[1])+",0")
+	}
+	return strings.Join(s, " ")
+}
+
+// rampColor interpolates half transparent color from blue to red
+func rampColor(value, min, max float64) string {
+	f := 1.0
+	if max > min {
+		f = (value - min) / (max - min)
+	}
+	r := uint8(math.Floor(255*f + 0.5))
+	return fmt.Sprintf("7f%02x00%02x", 255-r, r)
+}
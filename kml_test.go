@@ -0,0 +1,90 @@
+package geohash
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestHashesKML(t *testing.T) {
+	cryptor := NewDefaultGeoHash()
+
+	b, err := HashesKML(cryptor, []string{"7", "kp", "kr"}, KMLOptions{
+		Name: "cells", Values: map[string]float64{"7": 1, "kp": 3}, GroupByPrecision: true})
+	doc := kmlDoc{}
+	if err == nil {
+		err = xml.Unmarshal(b, &doc)
+	}
+	if err != nil || len(doc.Document.Folders) != 2 || len(doc.Document.Placemarks) != 0 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: 2 folders\n\n\tgot: %s, %v\n\n", filepath.Base(file), line, b, err)
+		t.FailNow()
+	}
+
+	got := []string{}
+	for _, f := range doc.Document.Folders {
+		got = append(got, f.Name)
+		for _, pm := range f.Placemarks {
+			got = append(got, pm.Name, pm.Style.PolyColor)
+		}
+	}
+	exp := []string{"precision 1", "7", "7fff0000", "precision 2", "kp", "7f0000ff", "kr", "7fffffff"}
+	if !reflect.DeepEqual(exp, got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+
+	ring, expRing := doc.Document.Folders[0].Placemarks[0].Polygon.Coordinates, "-45,-45,0 0,-45,0 0,0,0 -45,0,0 -45,-45,0"
+	if ring != expRing {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, expRing, ring)
+		t.FailNow()
+	}
+}
+
+func TestNeighborsKML(t *testing.T) {
+	cryptor := NewDefaultGeoHash()
+
+	b, err := BoxesKML(cryptor.Neighbors("gz", 2), KMLOptions{})
+	doc := kmlDoc{}
+	if err == nil {
+		err = xml.Unmarshal(b, &doc)
+	}
+	got, exp := []string{}, []string{"gw", "gy", "un", "gx", "up"}
+	for _, pm := range doc.Document.Placemarks {
+		got = append(got, pm.Name)
+	}
+	if err != nil || !reflect.DeepEqual(exp, got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, exp, got, err)
+		t.FailNow()
+	}
+}
+
+func TestAntimeridianKML(t *testing.T) {
+	pacific := LocationBox{MinLat: -10, MinLng: 170, MaxLat: 10, MaxLng: -170, Hash: "pacific"}
+	b, err := BoxesKML([]BoundingBox{pacific}, KMLOptions{})
+	doc := kmlDoc{}
+	if err == nil {
+		err = xml.Unmarshal(b, &doc)
+	}
+	got := []string{}
+	for _, pm := range doc.Document.Placemarks {
+		if pm.Polygon != nil {
+			got = append(got, pm.Polygon.Coordinates)
+		}
+		for _, p := range pm.MultiGeometry {
+			got = append(got, p.Coordinates)
+		}
+	}
+	exp := []string{"170,-10,0 180,-10,0 180,10,0 170,10,0 170,-10,0", "-180,-10,0 -170,-10,0 -170,10,0 -180,10,0 -180,-10,0"}
+	if err != nil || !reflect.DeepEqual(exp, got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, exp, got, err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,168 @@
+package geohash
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// manyChunk is the number of items a worker takes at once
+const manyChunk = 1024
+
+// EncodeMany encodes points with given number of goroutines, GOMAXPROCS if workers <= 0.
+// Hashes are in the order of points, on cancellation ctx error is returned
+func EncodeMany(ctx context.Context, c GeoCryptor, points []GeoPoint, precision, workers int) ([]string, error) {
+	hashes := make([]string, len(points))
+	err := parallel(ctx, len(points), workers, func(i int) {
+		hashes[i] = c.Encode(points[i].Lat, points[i].Lng, precision)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+// DecodeMany decodes cell centers of hashes with given number of goroutines as EncodeMany
+func DecodeMany(ctx context.Context, c GeoCryptor, hashes []string, workers int) ([]GeoPoint, error) {
+	points := make([]GeoPoint, len(hashes))
+	err := parallel(ctx, len(hashes), workers, func(i int) {
+		points[i].Lat, points[i].Lng = c.Decode(hashes[i], len(hashes[i]))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// EncodeChan encodes points read from in with given number of goroutines and
+// sends hashes in input order, the returned channel is closed when in is closed
+// or ctx is done, check ctx.Err() to tell them apart
+func EncodeChan(ctx context.Context, c GeoCryptor, in <-chan GeoPoint, precision, workers int) <-chan string {
+	return pipeline(ctx, in, workers, func(p GeoPoint) string {
+		return c.Encode(p.Lat, p.Lng, precision)
+	})
+}
+
+// DecodeChan decodes cell centers of hashes read from in as EncodeChan
+func DecodeChan(ctx context.Context, c GeoCryptor, in <-chan string, workers int) <-chan GeoPoint {
+	return pipeline(ctx, in, workers, func(v string) GeoPoint {
+		lat, lng := c.Decode(v, len(v))
+		return GeoPoint{Lat: lat, Lng: lng}
+	})
+}
+
+func workerCount(workers int) int {
+	if workers <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return workers
+}
+
+// parallel calls fn for every index in [0, n), workers take chunks of indexes
+// and stop between chunks once ctx is done
+func parallel(ctx context.Context, n, workers int, fn func(i int)) error {
+	workers = workerCount(workers)
+	next := int64(0)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers && w*manyChunk < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				start := int(atomic.AddInt64(&next, manyChunk)) - manyChunk
+				if start >= n {
+					return
+				}
+				end := start + manyChunk
+				if end > n {
+					end = n
+				}
+				for i := start; i < end; i++ {
+					fn(i)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// pipeline maps items of in by fn on workers, chunks are queued in input order
+// so results are sent in the same order
+func pipeline[T, R any](ctx context.Context, in <-chan T, workers int, fn func(T) R) <-chan R {
+	workers = workerCount(workers)
+	out := make(chan R, manyChunk)
+	jobs := make(chan func(), workers)
+	queue := make(chan chan []R, workers*2)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			for job := range jobs {
+				job()
+			}
+		}()
+	}
+
+	// dispatcher cuts chunks out of what is available without waiting for a full one
+	go func() {
+		defer close(queue)
+		defer close(jobs)
+		for {
+			var chunk []T
+			select {
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+				chunk = append(chunk, v)
+			case <-ctx.Done():
+				return
+			}
+		fill:
+			for len(chunk) < manyChunk {
+				select {
+				case v, ok := <-in:
+					if !ok {
+						break fill
+					}
+					chunk = append(chunk, v)
+				default:
+					break fill
+				}
+			}
+			result := make(chan []R, 1)
+			select {
+			case queue <- result:
+			case <-ctx.Done():
+				return
+			}
+			jobs <- func() {
+				r := make([]R, len(chunk))
+				for i, v := range chunk {
+					r[i] = fn(v)
+				}
+				result <- r
+			}
+		}
+	}()
+
+	go func() {
+		defer close(out)
+		for result := range queue {
+			select {
+			case r := <-result:
+				for _, v := range r {
+					select {
+					case out <- v:
+					case <-ctx.Done():
+						return
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
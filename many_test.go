@@ -0,0 +1,105 @@
+package geohash
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func manyPoints(n int) []GeoPoint {
+	points := make([]GeoPoint, n)
+	for i := range points {
+		points[i] = GeoPoint{Lat: float64(i%1800)/10 - 90, Lng: float64(i%3600)/10 - 180}
+	}
+	return points
+}
+
+func TestEncodeDecodeMany(t *testing.T) {
+	gh := NewDefaultGeoHash()
+	points := manyPoints(5000)
+	exp, expPoints := make([]string, len(points)), make([]GeoPoint, len(points))
+	for i, p := range points {
+		exp[i] = gh.Encode(p.Lat, p.Lng, 9)
+		expPoints[i].Lat, expPoints[i].Lng = gh.Decode(exp[i], 9)
+	}
+
+	for _, workers := range []int{0, 1, 3} {
+		got, err := EncodeMany(context.Background(), gh, points, 9, workers)
+		if err != nil || !reflect.DeepEqual(exp, got) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: workers %d\n\n\terr: %v\n\n", filepath.Base(file), line, workers, err)
+			t.FailNow()
+		}
+		gotPoints, err := DecodeMany(context.Background(), gh, exp, workers)
+		if err != nil || !reflect.DeepEqual(expPoints, gotPoints) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: workers %d\n\n\terr: %v\n\n", filepath.Base(file), line, workers, err)
+			t.FailNow()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got, err := EncodeMany(ctx, gh, points, 9, 2); err != context.Canceled || got != nil {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %v\n\n\tgot: %v\n\n", filepath.Base(file), line, context.Canceled, err)
+		t.FailNow()
+	}
+}
+
+func TestEncodeDecodeChan(t *testing.T) {
+	gh := NewDefaultGeoHash()
+	points := manyPoints(5000)
+	in := make(chan GeoPoint)
+	go func() {
+		for _, p := range points {
+			in <- p
+		}
+		close(in)
+	}()
+	hashes := make(chan string)
+	out := DecodeChan(context.Background(), gh, hashes, 3)
+	go func() {
+		for h := range EncodeChan(context.Background(), gh, in, 9, 3) {
+			hashes <- h
+		}
+		close(hashes)
+	}()
+
+	i := 0
+	for p := range out {
+		h := gh.Encode(points[i].Lat, points[i].Lng, 9)
+		lat, lng := gh.Decode(h, 9)
+		if p.Lat != lat || p.Lng != lng {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %d\n\n\texp: %v %v\n\n\tgot: %v\n\n", filepath.Base(file), line, i, lat, lng, p)
+			t.FailNow()
+		}
+		i++
+	}
+	if i != len(points) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %d results\n\n\tgot: %d\n\n", filepath.Base(file), line, len(points), i)
+		t.FailNow()
+	}
+
+	// output is closed on cancellation while input stays open
+	ctx, cancel := context.WithCancel(context.Background())
+	out2 := EncodeChan(ctx, gh, make(chan GeoPoint), 9, 2)
+	cancel()
+	for range out2 {
+	}
+}
+
+func BenchmarkEncodeMany(b *testing.B) {
+	gh := NewDefaultGeoHash()
+	points := manyPoints(100000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		EncodeMany(context.Background(), gh, points, 12, 0)
+	}
+}
@@ -0,0 +1,156 @@
+package geohash
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PostGISPointPrecision is the precision PostGIS picks for a point, 2*51/5 characters
+const PostGISPointPrecision = 20
+
+// PostGISGeoHash mirrors ST_GeoHash(geom, maxchars) of geometry with given bounding box,
+// the center of the box is encoded and maxchars <= 0 picks precision by PostGISPrecision.
+// Unlike Encode a point on a cell edge goes to the upper cell as PostGIS does
+func PostGISGeoHash(minLat, minLng, maxLat, maxLng float64, maxChars int) (string, error) {
+	if minLng < MinLng || minLat < MinLat || maxLng > MaxLng || maxLat > MaxLat {
+		return "", fmt.Errorf("Geohash requires inputs in decimal degrees, got (%g %g, %g %g).", minLng, minLat, maxLng, maxLat)
+	}
+	lng := minLng + (maxLng-minLng)/2
+	lat := minLat + (maxLat-minLat)/2
+	if maxChars <= 0 {
+		maxChars = PostGISPrecision(minLat, minLng, maxLat, maxLng)
+	}
+	return postgisEncode(lat, lng, maxChars), nil
+}
+
+// PostGISPointGeoHash mirrors ST_GeoHash(point, maxchars)
+func PostGISPointGeoHash(lat, lng float64, maxChars int) (string, error) {
+	return PostGISGeoHash(lat, lng, lat, lng, maxChars)
+}
+
+// PostGISPrecision returns the number of characters PostGIS uses for bounding box,
+// the world box is halved until an edge cuts the box, a point gets PostGISPointPrecision
+// and a box crossing the first halving gets 0, that is an empty hash
+func PostGISPrecision(minLat, minLng, maxLat, maxLng float64) int {
+	if minLng == maxLng && minLat == maxLat {
+		return PostGISPointPrecision
+	}
+	lngMin, lngMax, latMin, latMax := MinLng, MaxLng, MinLat, MaxLat
+	bits := 0
+	for {
+		lngWidth, latWidth := lngMax-lngMin, latMax-latMin
+		if minLng > lngMin+lngWidth/2 {
+			lngMin += lngWidth / 2
+		} else if maxLng < lngMax-lngWidth/2 {
+			lngMax -= lngWidth / 2
+		} else {
+			break
+		}
+		bits++
+
+		if minLat > latMin+latWidth/2 {
+			latMin += latWidth / 2
+		} else if maxLat < latMax-latWidth/2 {
+			latMax -= latWidth / 2
+		} else {
+			break
+		}
+		bits++
+	}
+	return bits / 5
+}
+
+// postgisEncode follows geohash_point of liblwgeom
+func postgisEncode(lat, lng float64, precision int) string {
+	lats, lngs := [2]float64{MinLat, MaxLat}, [2]float64{MinLng, MaxLng}
+	b := make([]byte, precision)
+	even := true
+	for i := 0; i < precision; i++ {
+		ch := 0
+		for bit := 4; bit >= 0; bit-- {
+			if even {
+				if mid := (lngs[0] + lngs[1]) / 2; lng >= mid {
+					ch |= 1 << uint(bit)
+					lngs[0] = mid
+				} else {
+					lngs[1] = mid
+				}
+			} else {
+				if mid := (lats[0] + lats[1]) / 2; lat >= mid {
+					ch |= 1 << uint(bit)
+					lats[0] = mid
+				} else {
+					lats[1] = mid
+				}
+			}
+			even = !even
+		}
+		b[i] = B32[ch]
+	}
+	return string(b)
+}
+
+// PostGISBox2dFromGeoHash mirrors ST_Box2dFromGeoHash(geohash, precision),
+// hash is case insensitive and precision < 0 or beyond hash length uses the whole hash
+func PostGISBox2dFromGeoHash(value string, precision int) (BoundingBox, error) {
+	if precision < 0 || precision > len(value) {
+		precision = len(value)
+	}
+	value = strings.ToLower(value[:precision])
+	lats, lngs := [2]float64{MinLat, MaxLat}, [2]float64{MinLng, MaxLng}
+	even := true
+	for i := 0; i < len(value); i++ {
+		cd := strings.IndexByte(DefaultB32Str, value[i])
+		if cd < 0 {
+			return nil, fmt.Errorf("Invalid character '%c'", value[i])
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := cd&(1<<uint(bit)) != 0
+			if even {
+				mid := (lngs[0] + lngs[1]) / 2
+				if set {
+					lngs[0] = mid
+				} else {
+					lngs[1] = mid
+				}
+			} else {
+				mid := (lats[0] + lats[1]) / 2
+				if set {
+					lats[0] = mid
+				} else {
+					lats[1] = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return &LocationBox{
+		MinLat: lats[0], MaxLat: lats[1], MinLng: lngs[0], MaxLng: lngs[1],
+		LatErr: (lats[1] - lats[0]) / 2, LngErr: (lngs[1] - lngs[0]) / 2,
+		Hash: value, Precision: precision,
+	}, nil
+}
+
+// PostGISPointFromGeoHash mirrors ST_PointFromGeoHash(geohash, precision), the box center
+func PostGISPointFromGeoHash(value string, precision int) (lat, lng float64, err error) {
+	b, err := PostGISBox2dFromGeoHash(value, precision)
+	if err != nil {
+		return 0, 0, err
+	}
+	lb := b.(*LocationBox)
+	return lb.MinLat + (lb.MaxLat-lb.MinLat)/2, lb.MinLng + (lb.MaxLng-lb.MinLng)/2, nil
+}
+
+// PostGISGeomFromGeoHash mirrors ST_GeomFromGeoHash(geohash, precision),
+// the ring is ordered as PostGIS envelopes starting from the south-west corner clockwise
+func PostGISGeomFromGeoHash(value string, precision int) (Polygon, error) {
+	b, err := PostGISBox2dFromGeoHash(value, precision)
+	if err != nil {
+		return Polygon{}, err
+	}
+	lb := b.(*LocationBox)
+	return Polygon{Type: TypePolygon, Coordinates: [][][2]float64{{
+		{lb.MinLng, lb.MinLat}, {lb.MinLng, lb.MaxLat}, {lb.MaxLng, lb.MaxLat},
+		{lb.MaxLng, lb.MinLat}, {lb.MinLng, lb.MinLat},
+	}}}, nil
+}
@@ -0,0 +1,115 @@
+package geohash
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestPostGISGeoHash(t *testing.T) {
+	// vectors of PostGIS regression and unit tests
+	tr := []struct {
+		MinLat, MinLng, MaxLat, MaxLng float64
+		MaxChars                       int
+		Exp                            string
+	}{
+		{48, -126, 48, -126, 0, "c0w3hf1s70w3hf1s70w3"},
+		{48, -126, 48, -126, 5, "c0w3h"},
+		{49, -127, 50, -126, 0, "c0"},
+		{23, 23, 23.1, 23.1, 0, "ss0"},
+		{0, 0, 1, 1, 0, ""},
+		{0, 0, 1, 1, 3, "s00"},
+		{-90, -180, 90, 180, 2, "s0"},
+	}
+	for _, v := range tr {
+		got, err := PostGISGeoHash(v.MinLat, v.MinLng, v.MaxLat, v.MaxLng, v.MaxChars)
+		if err != nil || got != v.Exp {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v\n\n\texp: %#v\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, v, v.Exp, got, err)
+			t.FailNow()
+		}
+	}
+
+	if got, _ := PostGISPointGeoHash(0, 0, 1); got != "s" {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, "s", got)
+		t.FailNow()
+	}
+	if _, err := PostGISGeoHash(0, 0, 0, 200, 0); err == nil {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp error of degrees\n\n", filepath.Base(file), line)
+		t.FailNow()
+	}
+}
+
+func TestPostGISPrecision(t *testing.T) {
+	tr := []struct {
+		MinLat, MinLng, MaxLat, MaxLng float64
+		Exp                            int
+	}{
+		{25.2, 23, 25.2, 23, 20},
+		{23, 23, 23.1, 23.1, 3},
+		{23, 23, 23.0001, 23.0001, 7},
+		{-90, -180, 90, 180, 0},
+	}
+	for _, v := range tr {
+		if got := PostGISPrecision(v.MinLat, v.MinLng, v.MaxLat, v.MaxLng); got != v.Exp {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, v, v.Exp, got)
+			t.FailNow()
+		}
+	}
+}
+
+func TestPostGISFromGeoHash(t *testing.T) {
+	b, err := PostGISBox2dFromGeoHash("9qqj7nmxncgyy4d0dbxqz0", 4)
+	lb, _ := b.(*LocationBox)
+	exp := LocationBox{MinLat: 36.03515625, MaxLat: 36.2109375, MinLng: -115.3125, MaxLng: -114.9609375,
+		LatErr: 0.087890625, LngErr: 0.17578125, Hash: "9qqj", Precision: 4}
+	if err != nil || lb == nil || *lb != exp {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, exp, b, err)
+		t.FailNow()
+	}
+
+	tr := []struct {
+		Hash      string
+		Precision int
+		Lat, Lng  float64
+	}{
+		{"9qqj7nmxncgyy4d0dbxqz0", -1, 36.114646, -115.172816},
+		{"9QQJ7NMXNCGYY4D0DBXQZ0", -1, 36.114646, -115.172816},
+		{"9qqj7nmxncgyy4d0dbxqz0", 4, 36.123046875, -115.13671875},
+		{"9qqj7nmxncgyy4d0dbxqz0", 0, 0, 0},
+	}
+	for _, v := range tr {
+		lat, lng, err := PostGISPointFromGeoHash(v.Hash, v.Precision)
+		if err != nil || fmt.Sprintf("%.6f %.6f", lat, lng) != fmt.Sprintf("%.6f %.6f", v.Lat, v.Lng) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v\n\n\tgot: %v %v, %v\n\n", filepath.Base(file), line, v, lat, lng, err)
+			t.FailNow()
+		}
+	}
+
+	p, err := PostGISGeomFromGeoHash("s", -1)
+	expRing := [][2]float64{{0, 0}, {0, 45}, {45, 45}, {45, 0}, {0, 0}}
+	if err != nil || !reflect.DeepEqual(expRing, p.Coordinates[0]) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, expRing, p, err)
+		t.FailNow()
+	}
+
+	// characters beyond precision are not checked
+	if _, err := PostGISBox2dFromGeoHash("9qa", 2); err != nil {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %v\n\n", filepath.Base(file), line, err)
+		t.FailNow()
+	}
+	if _, err := PostGISBox2dFromGeoHash("9qa", -1); err == nil || err.Error() != "Invalid character 'a'" {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %v\n\n", filepath.Base(file), line, err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,53 @@
+package geohash
+
+import "sort"
+
+// UniquePrefixes returns for every point the shortest hash, not shorter than minPrecision,
+// which no other point shares, similar to abbreviated git commit ids.
+// Hashes are at most maxPrecision long, points falling into the same cell at
+// maxPrecision share their full hash since they cannot be told apart.
+// Precision beyond cryptor is rejected with PrecisionError
+func UniquePrefixes(c GeoCryptor, points []GeoPoint, minPrecision, maxPrecision int) ([]string, error) {
+	if maxPrecision < minPrecision {
+		maxPrecision = minPrecision
+	}
+	if err := CheckPrecision(c, maxPrecision); err != nil {
+		return nil, err
+	}
+	full := make([]string, len(points))
+	order := make([]int, len(points))
+	for i, p := range points {
+		full[i], order[i] = c.Encode(p.Lat, p.Lng, maxPrecision), i
+	}
+	sort.Slice(order, func(i, j int) bool { return full[order[i]] < full[order[j]] })
+
+	n := make([]string, len(points))
+	for k, i := range order {
+		l := 0
+		if k > 0 {
+			l = commonPrefix(full[i], full[order[k-1]])
+		}
+		if k < len(order)-1 {
+			if cp := commonPrefix(full[i], full[order[k+1]]); cp > l {
+				l = cp
+			}
+		}
+		l++
+		if l < minPrecision {
+			l = minPrecision
+		}
+		if l > len(full[i]) {
+			l = len(full[i])
+		}
+		n[i] = full[i][:l]
+	}
+	return n, nil
+}
+
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,47 @@
+package geohash
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestUniquePrefixes(t *testing.T) {
+	gh := NewDefaultGeoHash()
+	points := []GeoPoint{
+		{57.64911, 10.40744}, // u4pruydqqvj8
+		{57.6491, 10.4074},   // u4pruydqm*
+		{48.8584, 2.2945},    // u09tunq
+		{-33.8568, 151.2153}, // r3gx2
+		{57.64911, 10.40744},
+	}
+	tr := []struct {
+		Min, Max int
+		Exp      []string
+	}{
+		{1, 12, []string{"u4pruydqqvj8", "u4pruydqm", "u0", "r", "u4pruydqqvj8"}},
+		{3, 12, []string{"u4pruydqqvj8", "u4pruydqm", "u09", "r3g", "u4pruydqqvj8"}},
+		{1, 6, []string{"u4pruy", "u4pruy", "u0", "r", "u4pruy"}},
+	}
+	for _, v := range tr {
+		got, err := UniquePrefixes(gh, points, v.Min, v.Max)
+		if err != nil || !reflect.DeepEqual(v.Exp, got) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %d %d\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, v.Min, v.Max, v.Exp, got)
+			t.FailNow()
+		}
+	}
+
+	if got, _ := UniquePrefixes(gh, points[2:3], 1, 12); !reflect.DeepEqual([]string{"u"}, got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %#v\n\n", filepath.Base(file), line, got)
+		t.FailNow()
+	}
+	if got, err := UniquePrefixes(gh, points, 1, 20); err != (PrecisionError{Precision: 20, Max: MaxGeoHashPrecision}) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp precision error\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, got, err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,183 @@
+package geohash
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// PrefixMap attaches values to hash prefixes of one cryptor and looks up
+// the value of the longest prefix matching a hash or point, as IP routing tables do.
+// It is safe for concurrent use, lookups run in parallel
+type PrefixMap[V any] struct {
+	c   GeoCryptor
+	key string
+
+	mu     sync.RWMutex
+	root   *prefixNode[V]
+	size   int
+	maxLen int
+}
+
+type prefixNode[V any] struct {
+	value    V
+	ok       bool
+	children []*prefixNode[V]
+}
+
+// NewPrefixMap returns empty map of prefixes of cryptor c
+func NewPrefixMap[V any](c GeoCryptor) *PrefixMap[V] {
+	return &PrefixMap[V]{c: c, key: c.HashKey(), root: &prefixNode[V]{}}
+}
+
+// Set attaches v to prefix, empty prefix is the default matching every hash.
+// Prefixes longer than cryptor supports are rejected with PrecisionError
+func (pm *PrefixMap[V]) Set(prefix string, v V) error {
+	if err := pm.validate(prefix); err != nil {
+		return err
+	}
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.size += pm.set(pm.root, prefix, v)
+	if len(prefix) > pm.maxLen {
+		pm.maxLen = len(prefix)
+	}
+	return nil
+}
+
+// Load replaces every entry with entries at once, lookups see either
+// the old or the new entries. Nothing is replaced when a prefix is invalid
+func (pm *PrefixMap[V]) Load(entries map[string]V) error {
+	root, size, maxLen := &prefixNode[V]{}, 0, 0
+	for prefix, v := range entries {
+		if err := pm.validate(prefix); err != nil {
+			return err
+		}
+		size += pm.set(root, prefix, v)
+		if len(prefix) > maxLen {
+			maxLen = len(prefix)
+		}
+	}
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.root, pm.size, pm.maxLen = root, size, maxLen
+	return nil
+}
+
+// Delete detaches value of prefix, it reports whether prefix had one
+func (pm *PrefixMap[V]) Delete(prefix string) bool {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	n := pm.root
+	for i := 0; i < len(prefix) && n != nil; i++ {
+		k := strings.IndexByte(pm.key, prefix[i])
+		if k < 0 || n.children == nil {
+			return false
+		}
+		n = n.children[k]
+	}
+	if n == nil || !n.ok {
+		return false
+	}
+	var zero V
+	n.value, n.ok = zero, false
+	pm.size--
+	return true
+}
+
+func (pm *PrefixMap[V]) validate(prefix string) error {
+	if err := CheckPrecision(pm.c, len(prefix)); err != nil {
+		return err
+	}
+	for i := 0; i < len(prefix); i++ {
+		if strings.IndexByte(pm.key, prefix[i]) < 0 {
+			return HashError{Value: prefix, Msg: fmt.Sprintf("invalid character %q at %d", prefix[i], i)}
+		}
+	}
+	return nil
+}
+
+// set attaches v under n and returns 1 for a new entry
+func (pm *PrefixMap[V]) set(n *prefixNode[V], prefix string, v V) int {
+	for i := 0; i < len(prefix); i++ {
+		if n.children == nil {
+			n.children = make([]*prefixNode[V], len(pm.key))
+		}
+		k := strings.IndexByte(pm.key, prefix[i])
+		if n.children[k] == nil {
+			n.children[k] = &prefixNode[V]{}
+		}
+		n = n.children[k]
+	}
+	added := 1
+	if n.ok {
+		added = 0
+	}
+	n.value, n.ok = v, true
+	return added
+}
+
+// Lookup returns the longest prefix of hash which has a value,
+// ok is false when no prefix matches
+func (pm *PrefixMap[V]) Lookup(hash string) (prefix string, v V, ok bool) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.lookup(hash)
+}
+
+func (pm *PrefixMap[V]) lookup(hash string) (prefix string, v V, ok bool) {
+	n := pm.root
+	for i := 0; n != nil; i++ {
+		if n.ok {
+			prefix, v, ok = hash[:i], n.value, true
+		}
+		if i == len(hash) || n.children == nil {
+			break
+		}
+		k := strings.IndexByte(pm.key, hash[i])
+		if k < 0 {
+			break
+		}
+		n = n.children[k]
+	}
+	return prefix, v, ok
+}
+
+// LookupPoint returns the longest prefix which has a value and contains point,
+// point is encoded as long as the longest prefix ever set
+func (pm *PrefixMap[V]) LookupPoint(lat, lng float64) (prefix string, v V, ok bool) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.lookup(pm.c.Encode(lat, lng, pm.maxLen))
+}
+
+// Len returns number of prefixes with value
+func (pm *PrefixMap[V]) Len() int {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.size
+}
+
+// Range calls fn for every prefix with value in hash key order, parents before
+// their children, until fn returns false. The map is locked for reading
+// meanwhile, so fn must not change it
+func (pm *PrefixMap[V]) Range(fn func(prefix string, v V) bool) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	var visit func(n *prefixNode[V], prefix []byte) bool
+	visit = func(n *prefixNode[V], prefix []byte) bool {
+		if n == nil {
+			return true
+		}
+		if n.ok && !fn(string(prefix), n.value) {
+			return false
+		}
+		for i, c := range n.children {
+			if !visit(c, append(prefix, pm.key[i])) {
+				return false
+			}
+		}
+		return true
+	}
+	visit(pm.root, make([]byte, 0, 16))
+}
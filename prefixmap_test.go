@@ -0,0 +1,124 @@
+package geohash
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestPrefixMapLookup(t *testing.T) {
+	pm := NewPrefixMap[string](NewDefaultGeoHash())
+	for prefix, zone := range map[string]string{"": "world", "9q": "california", "9q8": "bay area", "9q8yy": "downtown"} {
+		if err := pm.Set(prefix, zone); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tr := []struct {
+		Hash, Prefix, Zone string
+	}{
+		{"9q8yyk8yt", "9q8yy", "downtown"},
+		{"9q8yy", "9q8yy", "downtown"},
+		{"9q8y", "9q8", "bay area"},
+		{"9q9p1", "9q", "california"},
+		{"u4pruydqqvj", "", "world"},
+	}
+	for _, v := range tr {
+		if prefix, zone, ok := pm.Lookup(v.Hash); !ok || prefix != v.Prefix || zone != v.Zone {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v\n\n\texp: %v, %v\n\n\tgot: %v, %v, %v\n\n", filepath.Base(file), line, v.Hash, v.Prefix, v.Zone, prefix, zone, ok)
+			t.FailNow()
+		}
+	}
+
+	if prefix, zone, _ := pm.LookupPoint(37.7749, -122.4194); prefix != "9q8yy" || zone != "downtown" {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: 9q8yy, downtown\n\n\tgot: %v, %v\n\n", filepath.Base(file), line, prefix, zone)
+		t.FailNow()
+	}
+
+	if !pm.Delete("") || pm.Delete("") || pm.Delete("9q8y") || pm.Len() != 3 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d: delete\n\n", filepath.Base(file), line)
+		t.FailNow()
+	}
+	if prefix, zone, ok := pm.Lookup("u4pruydqqvj"); ok {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: no match\n\n\tgot: %v, %v\n\n", filepath.Base(file), line, prefix, zone)
+		t.FailNow()
+	}
+	if err := pm.Set("9qa", "bad"); err == nil {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d: invalid prefix accepted\n\n", filepath.Base(file), line)
+		t.FailNow()
+	}
+}
+
+func TestPrefixMapLoadRange(t *testing.T) {
+	pm := NewPrefixMap[int](NewDefaultGeoHash36())
+	pm.Set("bd", 1)
+	if err := pm.Load(map[string]int{"9": 1, "H": 2, "2": 3, "22": 4, "HH": 5}); err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	pm.Range(func(prefix string, v int) bool {
+		got = append(got, fmt.Sprintf("%s=%d", prefix, v))
+		return len(got) < 4
+	})
+	// geohash36 key order is "23456789bBCdDFgGhHjJKlLMnNPqQrRtTVWX"
+	exp := []string{"2=3", "22=4", "9=1", "H=2"}
+	if !reflect.DeepEqual(exp, got) || pm.Len() != 5 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+	if _, _, ok := pm.Lookup("bdrdC26"); ok {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d: entry kept after load\n\n", filepath.Base(file), line)
+		t.FailNow()
+	}
+	if err := pm.Load(map[string]int{"a": 1}); err == nil || pm.Len() != 5 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d: invalid load replaced entries\n\n", filepath.Base(file), line)
+		t.FailNow()
+	}
+}
+
+func TestPrefixMapConcurrent(t *testing.T) {
+	pm := NewPrefixMap[int](NewDefaultGeoHash())
+	wg := sync.WaitGroup{}
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if prefix, v, ok := pm.LookupPoint(37.7749, -122.4194); ok && len(prefix) != v {
+					t.Errorf("prefix %q with value %d", prefix, v)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		pm.Load(map[string]int{"9": 1, "9q8": 3})
+		pm.Set("9q8y", 4)
+	}
+	wg.Wait()
+}
+
+func TestPrefixMapPrecision(t *testing.T) {
+	pm := NewPrefixMap[string](NewDefaultGeoHash())
+	pm.Set("w", "asia")
+	err := pm.Set("wdhh9b9rvzzzzzzzzzzz", "deep")
+	err2 := pm.Load(map[string]string{"u": "europe", "wdhh9b9rvzzzzzzzzzzz": "deep"})
+	// nothing too long for Encode is kept, so points are still looked up
+	prefix, v, _ := pm.LookupPoint(12.04512315, 118.20385763)
+	if !errors.As(err, &PrecisionError{}) || !errors.As(err2, &PrecisionError{}) || pm.Len() != 1 || prefix != "w" || v != "asia" {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp precision errors\n\n\tgot: %v, %v, %d entries\n\n", filepath.Base(file), line, err, err2, pm.Len())
+		t.FailNow()
+	}
+}
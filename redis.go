@@ -0,0 +1,104 @@
+package geohash
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Redis geo constants, Redis limits latitude to the range of EPSG:3857
+const (
+	RedisLatMax     = 85.05112878
+	RedisLatMin     = -85.05112878
+	RedisStep       = 26
+	RedisHashLength = 11
+)
+
+// RedisEncode returns 52-bit integer hash of point as stored by Redis GEOADD
+func RedisEncode(latitude, longitude float64) (uint64, error) {
+	return redisEncode(latitude, longitude, RedisLatMin, RedisLatMax)
+}
+
+func redisEncode(latitude, longitude, latMin, latMax float64) (uint64, error) {
+	if longitude > MaxLng || longitude < MinLng || latitude > RedisLatMax || latitude < RedisLatMin {
+		return 0, fmt.Errorf("invalid longitude,latitude pair %v,%v", longitude, latitude)
+	}
+	latOffset := (latitude - latMin) / (latMax - latMin) * (1 << RedisStep)
+	lngOffset := (longitude - MinLng) / (MaxLng - MinLng) * (1 << RedisStep)
+	return Interleave(uint32(latOffset), uint32(lngOffset)), nil
+}
+
+// RedisDecodeAsBox returns cell of 52-bit integer hash
+func RedisDecodeAsBox(bits uint64) BoundingBox {
+	lb := redisArea(bits)
+	lb.Hash, lb.Precision = RedisString(bits), RedisHashLength
+	return lb
+}
+
+// RedisDecode returns center of 52-bit integer hash as Redis GEOPOS
+func RedisDecode(bits uint64) (lat, lng float64) {
+	return redisCenter(redisArea(bits))
+}
+
+// redisArea follows the float arithmetic of Redis geohashDecode,
+// explicit conversions keep products from being fused into FMA
+func redisArea(bits uint64) *LocationBox {
+	lat, lng := squash64(bits), squash64(bits>>1)
+	latScale, lngScale := RedisLatMax-RedisLatMin, MaxLng-MinLng
+	lb := &LocationBox{
+		MinLat: RedisLatMin + float64((float64(lat)*1.0/(1<<RedisStep))*latScale),
+		MaxLat: RedisLatMin + float64((float64(lat+1)*1.0/(1<<RedisStep))*latScale),
+		MinLng: MinLng + float64((float64(lng)*1.0/(1<<RedisStep))*lngScale),
+		MaxLng: MinLng + float64((float64(lng+1)*1.0/(1<<RedisStep))*lngScale),
+	}
+	lb.LatErr, lb.LngErr = (lb.MaxLat-lb.MinLat)/2, (lb.MaxLng-lb.MinLng)/2
+	return lb
+}
+
+func redisCenter(lb *LocationBox) (lat, lng float64) {
+	lng = (lb.MinLng + lb.MaxLng) / 2
+	lng = minFloat64(maxFloat64(lng, MinLng), MaxLng)
+	lat = (lb.MinLat + lb.MaxLat) / 2
+	lat = minFloat64(maxFloat64(lat, RedisLatMin), RedisLatMax)
+	return lat, lng
+}
+
+// RedisString returns 11 characters string of 52-bit integer hash as Redis GEOHASH,
+// center of the cell is re-encoded with full latitude range and
+// the last character is always '0' since only 52 bits are available
+func RedisString(bits uint64) string {
+	clat, clng := RedisDecode(bits)
+
+	std, _ := redisEncode(clat, clng, MinLat, MaxLat)
+	b := make([]byte, RedisHashLength)
+	for i := 0; i < RedisHashLength-1; i++ {
+		b[i] = B32[(std>>uint(52-(i+1)*5))&0x1f]
+	}
+	b[RedisHashLength-1] = B32[0]
+	return string(b)
+}
+
+// RedisToGeoHash converts 52-bit integer hash to geohash string of given precision
+// which matches prefix of Redis GEOHASH, precision is at most 11
+func RedisToGeoHash(bits uint64, precision int) string {
+	if precision > RedisHashLength {
+		precision = RedisHashLength
+	}
+	if precision <= 0 {
+		return ""
+	}
+	return RedisString(bits)[:precision]
+}
+
+// GeoHashToRedis converts geohash string to 52-bit integer hash of the cell center,
+// cells centered beyond Redis latitude limits are rejected
+func GeoHashToRedis(value string) (uint64, error) {
+	if err := validHash(DefaultB32Str, value); err != nil {
+		return 0, err
+	}
+	lb := NewDefaultGeoHash().DecodeAsBox(value, len(value)).(*LocationBox)
+	lat, lng := (lb.MinLat+lb.MaxLat)/2, (lb.MinLng+lb.MaxLng)/2
+	if lat > RedisLatMax || lat < RedisLatMin {
+		return 0, errors.New("geohash center beyond Redis latitude limits")
+	}
+	return RedisEncode(lat, lng)
+}
@@ -0,0 +1,70 @@
+package geohash
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// vectors from Redis documentation of GEOADD, GEOHASH and GEOPOS
+var redisVectors = []struct {
+	Lat, Lng float64
+	Bits     uint64
+	Hash     string
+	PosLat   string
+	PosLng   string
+}{
+	{38.115556, 13.361389, 3479099956230698, "sqc8b49rny0", "38.11555639549629859", "13.36138933897018433"},
+	{37.502669, 15.087269, 3479447370796909, "sqdtr74hyu0", "37.50266842333162032", "15.08726745843887329"},
+}
+
+func TestRedisEncode(t *testing.T) {
+	for _, v := range redisVectors {
+		bits, err := RedisEncode(v.Lat, v.Lng)
+		if err != nil || bits != v.Bits {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, v.Bits, bits, err)
+			t.FailNow()
+		}
+		if h := RedisString(bits); h != v.Hash {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, v.Hash, h)
+			t.FailNow()
+		}
+		lat, lng := RedisDecode(bits)
+		if fmt.Sprintf("%.17f", lat) != v.PosLat || fmt.Sprintf("%.17f", lng) != v.PosLng {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\n\texp: (%v, %v)\n\n\tgot: (%.17f, %.17f)\n\n", filepath.Base(file), line, v.PosLat, v.PosLng, lat, lng)
+			t.FailNow()
+		}
+	}
+
+	if _, err := RedisEncode(85.06, 0); err == nil {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp error for latitude beyond limit\n\n", filepath.Base(file), line)
+		t.FailNow()
+	}
+}
+
+func TestRedisGeoHashConvert(t *testing.T) {
+	v := redisVectors[0]
+	if h := RedisToGeoHash(v.Bits, 9); h != "sqc8b49rn" {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, "sqc8b49rn", h)
+		t.FailNow()
+	}
+
+	bits, err := GeoHashToRedis(NewDefaultGeoHash().Encode(v.Lat, v.Lng, 12))
+	if err != nil || RedisString(bits) != v.Hash {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, v.Hash, RedisString(bits), err)
+		t.FailNow()
+	}
+
+	if _, err := GeoHashToRedis("zz"); err == nil {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp error for polar cell\n\n", filepath.Base(file), line)
+		t.FailNow()
+	}
+}
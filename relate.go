@@ -0,0 +1,103 @@
+package geohash
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Relation is the spatial relationship between two cells
+type Relation int
+
+// relations reported by Relate, cells of the same scheme never overlap partially
+const (
+	Disjoint Relation = iota
+	Adjacent
+	Overlaps
+	Within
+	Contains
+	Equal
+)
+
+var relationNames = []string{"disjoint", "adjacent", "overlaps", "within", "contains", "equal"}
+
+func (r Relation) String() string {
+	if r < 0 || int(r) >= len(relationNames) {
+		return fmt.Sprintf("Relation(%d)", int(r))
+	}
+	return relationNames[r]
+}
+
+// ContainsPoint reports whether point is encoded into cell h.
+// Cells are half-open as encoders assign points on cell edges:
+//
+//	geohash:   lat in (MinLat, MaxLat], lng in (MinLng, MaxLng]
+//	geohash36: lat in [MinLat, MaxLat), lng in (MinLng, MaxLng]
+//
+// edges on the border of the world are closed, points out of it are never contained.
+// Geohash-36 edges are sixths which float64 cannot hold, so its cells contain exactly
+// the points its encoder assigns to them
+func ContainsPoint(h Hash, lat, lng float64) bool {
+	if !(lat >= MinLat && lat <= MaxLat && lng >= MinLng && lng <= MaxLng) {
+		return false
+	}
+	switch {
+	case h.scheme == SchemeGeoHash36:
+		return h.scheme.NewCryptor().Encode(lat, lng, h.Precision()) == h.value
+	case h.Precision() > MaxGeoHashPrecision:
+		// float64 edges are not exact any more
+		return NewDefaultGeoHashBig().Encode(lat, lng, h.Precision()) == h.value
+	}
+	lb := h.Box().(*LocationBox)
+	in := func(v, min, max, bottom float64) bool {
+		return v <= max && (v > min || v == bottom && min == bottom)
+	}
+	return in(lat, lb.MinLat, lb.MaxLat, MinLat) && in(lng, lb.MinLng, lb.MaxLng, MinLng)
+}
+
+// Relate returns relation of cell a to cell b, e.g. Contains when a contains b.
+// Cells of the same scheme are related by prefix, cells of different schemes
+// by geometry with edges compared at edgeTolerance of the smaller cell.
+// Cells touching by an edge or a corner, including across the antimeridian, are Adjacent
+func Relate(a, b Hash) Relation {
+	if a.scheme == b.scheme {
+		switch {
+		case a.value == b.value:
+			return Equal
+		case strings.HasPrefix(b.value, a.value):
+			return Contains
+		case strings.HasPrefix(a.value, b.value):
+			return Within
+		}
+	}
+	return relateBoxes(a.Box().(*LocationBox), b.Box().(*LocationBox))
+}
+
+func relateBoxes(a, b *LocationBox) Relation {
+	latEps := math.Min(a.MaxLat-a.MinLat, b.MaxLat-b.MinLat) * edgeTolerance
+	lngEps := math.Min(a.MaxLng-a.MinLng, b.MaxLng-b.MinLng) * edgeTolerance
+	latOverlap := math.Min(a.MaxLat, b.MaxLat) - math.Max(a.MinLat, b.MinLat)
+	lngOverlap := math.Inf(-1)
+	for _, shift := range []float64{-2 * MaxLng, 0, 2 * MaxLng} {
+		lngOverlap = math.Max(lngOverlap, math.Min(a.MaxLng, b.MaxLng+shift)-math.Max(a.MinLng, b.MinLng+shift))
+	}
+	switch {
+	case latOverlap < -latEps || lngOverlap < -lngEps:
+		return Disjoint
+	case latOverlap <= latEps || lngOverlap <= lngEps:
+		return Adjacent
+	}
+	covers := func(a, b *LocationBox) bool {
+		return a.MinLat <= b.MinLat+latEps && a.MaxLat >= b.MaxLat-latEps &&
+			a.MinLng <= b.MinLng+lngEps && a.MaxLng >= b.MaxLng-lngEps
+	}
+	switch ab, ba := covers(a, b), covers(b, a); {
+	case ab && ba:
+		return Equal
+	case ab:
+		return Contains
+	case ba:
+		return Within
+	}
+	return Overlaps
+}
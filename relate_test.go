@@ -0,0 +1,102 @@
+package geohash
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestContainsPoint(t *testing.T) {
+	gh := func(v string) Hash { return Hash{scheme: SchemeGeoHash, value: v} }
+	tr := []struct {
+		Hash     Hash
+		Lat, Lng float64
+		Exp      bool
+	}{
+		{gh("7"), 0, 0, true},
+		{gh("s"), 0, 0, false},
+		{gh("k"), 0, 0, false},
+		{gh("e"), 0, 0, false},
+		{gh("s"), 45, 45, true},
+		{gh("0"), -90, -180, true},
+		{gh("z"), 90, 180, true},
+		{gh("p"), -90, 180, true},
+		{gh("0"), -90, 180, false},
+		{gh("z"), 91, 180, false},
+		{gh("wdhh9b9rv"), 12.04512315, 118.20385763, true},
+		{gh("wdhh9b9rv"), 12.04512315, 118.2, false},
+		{gh("wdhh9b9rvwdhh9b9rvwd"), 12.04512315, 118.20385763, false},
+	}
+	for _, v := range tr {
+		if got := ContainsPoint(v.Hash, v.Lat, v.Lng); got != v.Exp {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v (%v, %v)\n\n\texp: %v\n\n\tgot: %v\n\n", filepath.Base(file), line, v.Hash, v.Lat, v.Lng, v.Exp, got)
+			t.FailNow()
+		}
+	}
+
+	// every point, also on cell edges, is contained by the cell it is encoded into
+	r := rand.New(rand.NewSource(1))
+	for _, s := range []Scheme{SchemeGeoHash, SchemeGeoHash36} {
+		c := s.NewCryptor()
+		for n := 0; n < 20000; n++ {
+			p := 1 + r.Intn(12)
+			lat, lng := r.Float64()*180-90, r.Float64()*360-180
+			if n%2 == 0 {
+				lb := c.EncodeAsBox(lat, lng, 1+r.Intn(p)).(*LocationBox)
+				lat, lng = lb.MaxLat, lb.MinLng
+			}
+			h := Hash{scheme: s, value: c.Encode(lat, lng, p)}
+			parent := Hash{scheme: s, value: h.value[:p-1]}
+			sibling := Hash{scheme: s, value: h.value[:p-1] + string(s.Key()[(n+1)%len(s.Key())])}
+			if !ContainsPoint(h, lat, lng) || !ContainsPoint(parent, lat, lng) ||
+				sibling != h && ContainsPoint(sibling, lat, lng) {
+				_, file, line, _ := runtime.Caller(0)
+				fmt.Printf("%s:%d: %v (%v, %v)\n\n", filepath.Base(file), line, h, lat, lng)
+				t.FailNow()
+			}
+		}
+	}
+}
+
+func TestRelate(t *testing.T) {
+	gh := func(v string) Hash { return Hash{scheme: SchemeGeoHash, value: v} }
+	gh36 := func(lat, lng float64, p int) Hash {
+		return Hash{scheme: SchemeGeoHash36, value: NewDefaultGeoHash36().Encode(lat, lng, p)}
+	}
+	tr := []struct {
+		A, B Hash
+		Exp  Relation
+	}{
+		{gh("9q8"), gh("9q8"), Equal},
+		{gh("9q8"), gh("9q8yy"), Contains},
+		{gh("9q8yy"), gh("9q8"), Within},
+		{gh("9q8"), gh("9q9"), Adjacent},
+		{gh("7"), gh("e"), Adjacent},
+		{gh("7"), gh("s"), Adjacent},
+		{gh("8"), gh("x"), Adjacent},
+		{gh("0"), gh("p"), Adjacent},
+		{gh("9q8"), gh("u"), Disjoint},
+		{gh("7"), gh("u"), Disjoint},
+		{gh("s"), gh36(10, 10, 1), Overlaps},
+		{gh("s"), gh36(12, 15, 2), Contains},
+		{gh36(12, 15, 2), gh("s"), Within},
+		{gh36(12, 15, 3), gh("s00"), Disjoint},
+		{gh36(-12, -15, 1), gh("s"), Adjacent},
+		{gh36(10, -179, 1), gh("x"), Adjacent},
+	}
+	for _, v := range tr {
+		if got := Relate(v.A, v.B); got != v.Exp {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v, %v\n\n\texp: %v\n\n\tgot: %v\n\n", filepath.Base(file), line, v.A, v.B, v.Exp, got)
+			t.FailNow()
+		}
+	}
+	if s := Overlaps.String(); s != "overlaps" {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %v\n\n\tgot: %v\n\n", filepath.Base(file), line, "overlaps", s)
+		t.FailNow()
+	}
+}
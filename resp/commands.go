@@ -0,0 +1,484 @@
+package resp
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/myyang/geohash"
+)
+
+type command struct {
+	// arity follows Redis, negative for minimal number of arguments including name
+	arity int
+	write bool
+	fn    func(s *Server, w *bufio.Writer, args []string)
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"ping":      {-1, false, ping},
+		"quit":      {1, false, func(s *Server, w *bufio.Writer, args []string) { writeSimple(w, "OK") }},
+		"geoadd":    {-5, true, geoadd},
+		"geopos":    {-2, false, geopos},
+		"geohash":   {-2, false, geohashCmd},
+		"geodist":   {-4, false, geodist},
+		"geosearch": {-7, false, geosearch},
+		"zrem":      {-3, true, zrem},
+		"zscore":    {3, false, zscore},
+		"zcard":     {2, false, zcard},
+		"del":       {-2, true, del},
+	}
+}
+
+func (s *Server) exec(w *bufio.Writer, args []string) {
+	name := strings.ToLower(args[0])
+	cmd, ok := commands[name]
+	if !ok {
+		begin := []string{}
+		for _, a := range args[1:] {
+			begin = append(begin, "'"+a+"'")
+		}
+		writeError(w, fmt.Sprintf("ERR unknown command '%s', with args beginning with: %s", args[0], strings.Join(begin, " ")))
+		return
+	}
+	if (cmd.arity > 0 && len(args) != cmd.arity) || (cmd.arity < 0 && len(args) < -cmd.arity) {
+		writeError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", name))
+		return
+	}
+	if cmd.write {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	} else {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+	}
+	cmd.fn(s, w, args)
+}
+
+func ping(s *Server, w *bufio.Writer, args []string) {
+	switch len(args) {
+	case 1:
+		writeSimple(w, "PONG")
+	case 2:
+		writeBulk(w, args[1])
+	default:
+		writeError(w, "ERR wrong number of arguments for 'ping' command")
+	}
+}
+
+// GEOADD key [NX|XX] [CH] longitude latitude member [longitude latitude member ...]
+func geoadd(s *Server, w *bufio.Writer, args []string) {
+	nx, xx, ch := false, false, false
+	i := 2
+	for ; i < len(args); i++ {
+		switch strings.ToLower(args[i]) {
+		case "nx":
+			nx = true
+			continue
+		case "xx":
+			xx = true
+			continue
+		case "ch":
+			ch = true
+			continue
+		}
+		break
+	}
+	if nx && xx {
+		writeError(w, "ERR XX and NX options at the same time are not compatible")
+		return
+	}
+	if (len(args)-i)%3 != 0 || len(args) == i {
+		writeError(w, "ERR syntax error. Try GEOADD key [x1] [y1] [name1] [x2] [y2] [name2] ... ")
+		return
+	}
+
+	type item struct {
+		member string
+		score  uint64
+	}
+	items := []item{}
+	for ; i < len(args); i += 3 {
+		lng, err1 := parseFloat(args[i])
+		lat, err2 := parseFloat(args[i+1])
+		if err1 != nil || err2 != nil {
+			writeError(w, "ERR value is not a valid float")
+			return
+		}
+		score, err := geohash.RedisEncode(lat, lng)
+		if err != nil {
+			writeError(w, fmt.Sprintf("ERR invalid longitude,latitude pair %f,%f", lng, lat))
+			return
+		}
+		items = append(items, item{member: args[i+2], score: score})
+	}
+
+	set := s.sets[args[1]]
+	if set == nil {
+		if xx {
+			writeInt(w, 0)
+			return
+		}
+		set = newGeoSet()
+		s.sets[args[1]] = set
+	}
+	n := int64(0)
+	for _, it := range items {
+		_, exists := set.scores[it.member]
+		if (nx && exists) || (xx && !exists) {
+			continue
+		}
+		added, changed := set.add(it.member, it.score)
+		if added || (ch && changed) {
+			n++
+		}
+	}
+	if len(set.scores) == 0 {
+		delete(s.sets, args[1])
+	}
+	writeInt(w, n)
+}
+
+// GEOPOS key [member ...]
+func geopos(s *Server, w *bufio.Writer, args []string) {
+	set := s.sets[args[1]]
+	writeArray(w, len(args)-2)
+	for _, m := range args[2:] {
+		score, ok := lookup(set, m)
+		if !ok {
+			writeNilArray(w)
+			continue
+		}
+		lat, lng := geohash.RedisDecode(score)
+		writeArray(w, 2)
+		writeBulk(w, formatCoord(lng))
+		writeBulk(w, formatCoord(lat))
+	}
+}
+
+// GEOHASH key [member ...]
+func geohashCmd(s *Server, w *bufio.Writer, args []string) {
+	set := s.sets[args[1]]
+	writeArray(w, len(args)-2)
+	for _, m := range args[2:] {
+		score, ok := lookup(set, m)
+		if !ok {
+			writeNil(w)
+			continue
+		}
+		writeBulk(w, geohash.RedisString(score))
+	}
+}
+
+// GEODIST key member1 member2 [M|KM|FT|MI]
+func geodist(s *Server, w *bufio.Writer, args []string) {
+	if len(args) > 5 {
+		writeError(w, "ERR syntax error")
+		return
+	}
+	unit := 1.0
+	if len(args) == 5 {
+		var ok bool
+		if unit, ok = parseUnit(args[4]); !ok {
+			writeError(w, "ERR unsupported unit provided. please use M, KM, FT, MI")
+			return
+		}
+	}
+	set := s.sets[args[1]]
+	s1, ok1 := lookup(set, args[2])
+	s2, ok2 := lookup(set, args[3])
+	if !ok1 || !ok2 {
+		writeNil(w)
+		return
+	}
+	lat1, lng1 := geohash.RedisDecode(s1)
+	lat2, lng2 := geohash.RedisDecode(s2)
+	writeBulk(w, strconv.FormatFloat(distance(lat1, lng1, lat2, lng2)/unit, 'f', 4, 64))
+}
+
+// geosearch implements GEOSEARCH key FROMMEMBER member|FROMLONLAT longitude latitude
+// BYRADIUS radius unit|BYBOX width height unit
+// [ASC|DESC] [COUNT count [ANY]] [WITHCOORD] [WITHDIST] [WITHHASH]
+func geosearch(s *Server, w *bufio.Writer, args []string) {
+	set := s.sets[args[1]]
+	a, unit := area{}, 1.0
+	from, by, sortDir, count := 0, 0, 0, 0
+	fromMember, byMember := "", false
+	hasCount, anyMatch, failed := false, false, false
+	withCoord, withDist, withHash := false, false, false
+	fail := func(msg string) {
+		if !failed {
+			writeError(w, msg)
+		}
+		failed = true
+	}
+	floats := func(i, n int) []float64 {
+		if i+n > len(args) {
+			fail("ERR syntax error")
+			return nil
+		}
+		f := make([]float64, 0, n)
+		for _, v := range args[i : i+n] {
+			x, err := parseFloat(v)
+			if err != nil {
+				fail("ERR value is not a valid float")
+				return nil
+			}
+			f = append(f, x)
+		}
+		return f
+	}
+	units := func(i int) {
+		if i >= len(args) {
+			fail("ERR syntax error")
+			return
+		}
+		u, ok := parseUnit(args[i])
+		if !ok {
+			fail("ERR unsupported unit provided. please use M, KM, FT, MI")
+		}
+		unit = u
+	}
+
+	for i := 2; i < len(args) && !failed; i++ {
+		switch strings.ToLower(args[i]) {
+		case "frommember":
+			if i+1 >= len(args) {
+				fail("ERR syntax error")
+				break
+			}
+			from++
+			fromMember, byMember = args[i+1], true
+			i++
+		case "fromlonlat":
+			if f := floats(i+1, 2); f != nil {
+				from++
+				a.lng, a.lat = f[0], f[1]
+				if _, err := geohash.RedisEncode(a.lat, a.lng); err != nil {
+					fail(fmt.Sprintf("ERR invalid longitude,latitude pair %f,%f", a.lng, a.lat))
+				}
+			}
+			i += 2
+		case "byradius":
+			if f := floats(i+1, 1); f != nil {
+				by++
+				a.radius = f[0]
+				if a.radius < 0 {
+					fail("ERR radius cannot be negative")
+				}
+				units(i + 2)
+			}
+			i += 2
+		case "bybox":
+			if f := floats(i+1, 2); f != nil {
+				by++
+				a.byBox, a.width, a.height = true, f[0], f[1]
+				if a.width < 0 || a.height < 0 {
+					fail("ERR height or width cannot be negative")
+				}
+				units(i + 3)
+			}
+			i += 3
+		case "asc":
+			sortDir = 1
+		case "desc":
+			sortDir = -1
+		case "count":
+			if i+1 >= len(args) {
+				fail("ERR syntax error")
+				break
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				fail("ERR value is not an integer or out of range")
+				break
+			}
+			if n <= 0 {
+				fail("ERR COUNT must be > 0")
+				break
+			}
+			count, hasCount = n, true
+			i++
+			if i+1 < len(args) && strings.EqualFold(args[i+1], "any") {
+				anyMatch = true
+				i++
+			}
+		case "withcoord":
+			withCoord = true
+		case "withdist":
+			withDist = true
+		case "withhash":
+			withHash = true
+		default:
+			fail("ERR syntax error")
+		}
+	}
+	if failed {
+		return
+	}
+	if from != 1 {
+		writeError(w, "ERR exactly one of FROMMEMBER or FROMLONLAT can be specified for GEOSEARCH")
+		return
+	}
+	if by != 1 {
+		writeError(w, "ERR exactly one of BYRADIUS and BYBOX arguments must be provided for GEOSEARCH command")
+		return
+	}
+	if anyMatch && !hasCount {
+		writeError(w, "ERR the ANY argument requires COUNT argument")
+		return
+	}
+	if set == nil {
+		writeArray(w, 0)
+		return
+	}
+	if byMember {
+		score, ok := lookup(set, fromMember)
+		if !ok {
+			writeError(w, "ERR could not decode requested zset member")
+			return
+		}
+		a.lat, a.lng = geohash.RedisDecode(score)
+	}
+	a.radius, a.width, a.height = a.radius*unit, a.width*unit, a.height*unit
+
+	matches := set.find(a)
+	if hasCount && !anyMatch && sortDir == 0 {
+		sortDir = 1
+	}
+	if sortDir != 0 {
+		sort.SliceStable(matches, func(i, j int) bool {
+			if matches[i].dist != matches[j].dist {
+				return (matches[i].dist < matches[j].dist) == (sortDir > 0)
+			}
+			return matches[i].member < matches[j].member
+		})
+	}
+	if hasCount && len(matches) > count {
+		matches = matches[:count]
+	}
+
+	fields := 1
+	for _, b := range []bool{withDist, withHash, withCoord} {
+		if b {
+			fields++
+		}
+	}
+	writeArray(w, len(matches))
+	for _, m := range matches {
+		if fields == 1 {
+			writeBulk(w, m.member)
+			continue
+		}
+		writeArray(w, fields)
+		writeBulk(w, m.member)
+		if withDist {
+			writeBulk(w, strconv.FormatFloat(m.dist/unit, 'f', 4, 64))
+		}
+		if withHash {
+			writeInt(w, int64(m.score))
+		}
+		if withCoord {
+			writeArray(w, 2)
+			writeBulk(w, formatCoord(m.lng))
+			writeBulk(w, formatCoord(m.lat))
+		}
+	}
+}
+
+// ZREM key member [member ...]
+func zrem(s *Server, w *bufio.Writer, args []string) {
+	set := s.sets[args[1]]
+	n := int64(0)
+	if set != nil {
+		for _, m := range args[2:] {
+			if set.remove(m) {
+				n++
+			}
+		}
+		if len(set.scores) == 0 {
+			delete(s.sets, args[1])
+		}
+	}
+	writeInt(w, n)
+}
+
+// ZSCORE key member
+func zscore(s *Server, w *bufio.Writer, args []string) {
+	score, ok := lookup(s.sets[args[1]], args[2])
+	if !ok {
+		writeNil(w)
+		return
+	}
+	writeBulk(w, strconv.FormatUint(score, 10))
+}
+
+// ZCARD key
+func zcard(s *Server, w *bufio.Writer, args []string) {
+	n := 0
+	if set := s.sets[args[1]]; set != nil {
+		n = len(set.scores)
+	}
+	writeInt(w, int64(n))
+}
+
+// DEL key [key ...]
+func del(s *Server, w *bufio.Writer, args []string) {
+	n := int64(0)
+	for _, k := range args[1:] {
+		if _, ok := s.sets[k]; ok {
+			delete(s.sets, k)
+			n++
+		}
+	}
+	writeInt(w, n)
+}
+
+func lookup(set *geoSet, member string) (uint64, bool) {
+	if set == nil {
+		return 0, false
+	}
+	score, ok := set.scores[member]
+	return score, ok
+}
+
+func parseFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err == nil && math.IsNaN(f) {
+		return 0, strconv.ErrSyntax
+	}
+	return f, err
+}
+
+// parseUnit returns meters of unit
+func parseUnit(u string) (float64, bool) {
+	switch strings.ToLower(u) {
+	case "m":
+		return 1, true
+	case "km":
+		return 1000, true
+	case "ft":
+		return 0.3048, true
+	case "mi":
+		return 1609.34, true
+	}
+	return 0, false
+}
+
+// formatCoord prints coordinate as Redis does for long double,
+// 17 decimals with trailing zeros removed
+func formatCoord(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 17, 64)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
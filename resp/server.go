@@ -0,0 +1,218 @@
+// Package resp provides a small server speaking the Redis protocol (RESP2)
+// which emulates Redis GEO commands on top of geohash package.
+//
+// Supported commands are GEOADD, GEOPOS, GEOHASH, GEODIST, GEOSEARCH,
+// ZREM, ZSCORE, ZCARD, DEL, PING and QUIT. Data lives in memory only.
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// limits of a single request
+const (
+	maxArgs    = 1 << 20
+	maxBulkLen = 512 << 20
+	maxInline  = 64 << 10
+)
+
+// Server is an in-memory geo store served over RESP
+type Server struct {
+	mu   sync.RWMutex
+	sets map[string]*geoSet
+
+	lmu       sync.Mutex
+	listeners map[net.Listener]struct{}
+	closed    bool
+}
+
+// NewServer returns empty server
+func NewServer() *Server {
+	return &Server{sets: map[string]*geoSet{}, listeners: map[net.Listener]struct{}{}}
+}
+
+// ErrServerClosed is returned by Serve after Close
+var ErrServerClosed = errors.New("resp: server closed")
+
+// ListenAndServe listens on TCP address and serves connections
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts connections on l until Close is called
+func (s *Server) Serve(l net.Listener) error {
+	s.lmu.Lock()
+	if s.closed {
+		s.lmu.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	s.listeners[l] = struct{}{}
+	s.lmu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.lmu.Lock()
+			closed := s.closed
+			s.lmu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		go s.ServeConn(conn)
+	}
+}
+
+// Close stops all listeners, connections being served finish their current command
+func (s *Server) Close() error {
+	s.lmu.Lock()
+	defer s.lmu.Unlock()
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	return nil
+}
+
+// ServeConn serves commands on conn until client quits or disconnects
+func (s *Server) ServeConn(conn io.ReadWriteCloser) {
+	defer conn.Close()
+	// a broken command must not take down other connections
+	defer func() {
+		if e := recover(); e != nil {
+			log.Printf("georesp: panic serving connection: %v", e)
+		}
+	}()
+	r, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			if pe, ok := err.(protocolError); ok {
+				writeError(w, "ERR Protocol error: "+string(pe))
+				w.Flush()
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+		quit := strings.EqualFold(args[0], "QUIT")
+		s.exec(w, args)
+		// flush only when no pipelined command is waiting
+		if r.Buffered() == 0 || quit {
+			if w.Flush() != nil {
+				return
+			}
+		}
+		if quit {
+			return
+		}
+	}
+}
+
+type protocolError string
+
+func (pe protocolError) Error() string {
+	return string(pe)
+}
+
+// readCommand reads a RESP array of bulk strings or an inline command
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r, maxInline)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		return strings.Fields(line), nil
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < -1 || n > maxArgs {
+		return nil, protocolError("invalid multibulk length")
+	}
+	// *-1 is a null array and *0 an empty one, both are empty commands
+	if n <= 0 {
+		return nil, nil
+	}
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		line, err := readLine(r, maxInline)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, protocolError("expected '$', got '" + line + "'")
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 || size > maxBulkLen {
+			return nil, protocolError("invalid bulk length")
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		if buf[size] != '\r' || buf[size+1] != '\n' {
+			return nil, protocolError("bulk string not terminated by CRLF")
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+func readLine(r *bufio.Reader, limit int) (string, error) {
+	b := []byte{}
+	for {
+		frag, err := r.ReadSlice('\n')
+		b = append(b, frag...)
+		if len(b) > limit {
+			return "", protocolError("too big inline request")
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+}
+
+func writeSimple(w *bufio.Writer, s string) {
+	w.WriteString("+" + s + "\r\n")
+}
+
+func writeError(w *bufio.Writer, s string) {
+	w.WriteString("-" + s + "\r\n")
+}
+
+func writeInt(w *bufio.Writer, n int64) {
+	w.WriteString(":" + strconv.FormatInt(n, 10) + "\r\n")
+}
+
+func writeBulk(w *bufio.Writer, s string) {
+	w.WriteString("$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n")
+}
+
+func writeNil(w *bufio.Writer) {
+	w.WriteString("$-1\r\n")
+}
+
+func writeNilArray(w *bufio.Writer) {
+	w.WriteString("*-1\r\n")
+}
+
+func writeArray(w *bufio.Writer, n int) {
+	w.WriteString("*" + strconv.Itoa(n) + "\r\n")
+}
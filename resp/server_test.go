@@ -0,0 +1,215 @@
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/myyang/geohash"
+)
+
+// readReply parses one RESP reply, nil replies become nil and errors become error
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return errors.New(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, _ := strconv.Atoi(line[1:])
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		_, err := io.ReadFull(r, buf)
+		return string(buf[:n]), err
+	case '*':
+		n, _ := strconv.Atoi(line[1:])
+		if n < 0 {
+			return nil, nil
+		}
+		a := []interface{}{}
+		for i := 0; i < n; i++ {
+			v, err := readReply(r)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		return a, nil
+	}
+	return nil, fmt.Errorf("unexpected reply %q", line)
+}
+
+type client struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func newClient() *client {
+	c, srv := net.Pipe()
+	go NewServer().ServeConn(srv)
+	return &client{conn: c, r: bufio.NewReader(c)}
+}
+
+func (c *client) do(args ...string) interface{} {
+	b := &strings.Builder{}
+	b.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, a := range args {
+		b.WriteString("$" + strconv.Itoa(len(a)) + "\r\n" + a + "\r\n")
+	}
+	go c.conn.Write([]byte(b.String()))
+	v, err := readReply(c.r)
+	if err != nil {
+		return err
+	}
+	return v
+}
+
+func TestGeoCommands(t *testing.T) {
+	c := newClient()
+	defer c.conn.Close()
+
+	// examples of Redis documentation
+	tr := []struct {
+		Args string
+		Exp  interface{}
+	}{
+		{"PING", "PONG"},
+		{"GEOADD Sicily 13.361389 38.115556 Palermo 15.087269 37.502669 Catania", int64(2)},
+		{"GEOADD Sicily NX 13.361389 38.115556 Palermo", int64(0)},
+		{"ZSCORE Sicily Palermo", "3479099956230698"},
+		{"GEODIST Sicily Palermo Catania", "166274.1516"},
+		{"GEODIST Sicily Palermo Catania km", "166.2742"},
+		{"GEODIST Sicily Palermo Catania mi", "103.3182"},
+		{"GEODIST Sicily Foo Bar", nil},
+		{"GEOHASH Sicily Palermo Catania NonExisting", []interface{}{"sqc8b49rny0", "sqdtr74hyu0", nil}},
+		{"GEOPOS Sicily Palermo NonExisting", []interface{}{
+			[]interface{}{"13.36138933897018433", "38.11555639549629859"}, nil}},
+		{"GEOADD Sicily 12.758489 38.788135 edge1 17.241510 38.788135 edge2", int64(2)},
+		{"GEOSEARCH Sicily FROMLONLAT 15 37 BYRADIUS 200 km ASC", []interface{}{"Catania", "Palermo"}},
+		{"GEOSEARCH Sicily FROMLONLAT 15 37 BYBOX 400 400 km ASC WITHCOORD WITHDIST", []interface{}{
+			[]interface{}{"Catania", "56.4413", []interface{}{"15.08726745843887329", "37.50266842333162032"}},
+			[]interface{}{"Palermo", "190.4424", []interface{}{"13.36138933897018433", "38.11555639549629859"}},
+			[]interface{}{"edge2", "279.7403", []interface{}{"17.24151045083999634", "38.78813451624225195"}},
+			[]interface{}{"edge1", "279.7405", []interface{}{"12.7584877610206604", "38.78813451624225195"}},
+		}},
+		{"GEOSEARCH Sicily FROMMEMBER Palermo BYRADIUS 200 km DESC COUNT 1 WITHHASH", []interface{}{
+			[]interface{}{"Catania", int64(3479447370796909)}}},
+		{"GEOSEARCH Sicily FROMMEMBER Palermo BYRADIUS 1 m", []interface{}{"Palermo"}},
+		{"GEOSEARCH Nope FROMMEMBER Palermo BYRADIUS 1 m", []interface{}{}},
+		{"ZREM Sicily edge1 edge2 edge3", int64(2)},
+		{"ZCARD Sicily", int64(2)},
+		{"DEL Sicily", int64(1)},
+		{"ZCARD Sicily", int64(0)},
+	}
+	for _, v := range tr {
+		got := c.do(strings.Fields(v.Args)...)
+		if !reflect.DeepEqual(v.Exp, got) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %s\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, v.Args, v.Exp, got)
+			t.FailNow()
+		}
+	}
+}
+
+func TestGeoCommandErrors(t *testing.T) {
+	c := newClient()
+	defer c.conn.Close()
+
+	tr := []struct {
+		Args string
+		Err  string
+	}{
+		{"GEOADD Sicily 200 100 Nowhere", "ERR invalid longitude,latitude pair 200.000000,100.000000"},
+		{"GEOADD Sicily NX XX 1 1 a", "ERR XX and NX options at the same time are not compatible"},
+		{"GEOADD Sicily 1 1", "ERR wrong number of arguments for 'geoadd' command"},
+		{"GEODIST Sicily a b parsec", "ERR unsupported unit provided. please use M, KM, FT, MI"},
+		{"GEOSEARCH Sicily BYRADIUS 1 m ASC COUNT 1", "ERR exactly one of FROMMEMBER or FROMLONLAT can be specified for GEOSEARCH"},
+		{"GEOSEARCH Sicily FROMLONLAT 1 1 ASC DESC", "ERR exactly one of BYRADIUS and BYBOX arguments must be provided for GEOSEARCH command"},
+		{"GEOSEARCH Sicily FROMLONLAT 1 1 BYRADIUS 1 m COUNT 0", "ERR COUNT must be > 0"},
+		{"FOO bar", "ERR unknown command 'FOO', with args beginning with: 'bar'"},
+	}
+	for _, v := range tr {
+		got, ok := c.do(strings.Fields(v.Args)...).(error)
+		if !ok || got.Error() != v.Err {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %s\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, v.Args, v.Err, got)
+			t.FailNow()
+		}
+	}
+}
+
+func TestMultibulkLength(t *testing.T) {
+	c := newClient()
+	defer c.conn.Close()
+
+	// null and empty arrays are skipped, other negative lengths close connection
+	go c.conn.Write([]byte("*-1\r\n*0\r\nPING\r\n*-2\r\n"))
+	for _, exp := range []interface{}{"PONG", errors.New("ERR Protocol error: invalid multibulk length")} {
+		got, err := readReply(c.r)
+		if err != nil || fmt.Sprint(got) != fmt.Sprint(exp) {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, exp, got, err)
+			t.FailNow()
+		}
+	}
+}
+
+func TestServe(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	s := NewServer()
+	done := make(chan error)
+	go func() { done <- s.Serve(l) }()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// inline and pipelined commands
+	conn.Write([]byte("PING\r\nGEOADD k 1 1 a\r\n"))
+	r := bufio.NewReader(conn)
+	got1, _ := readReply(r)
+	got2, _ := readReply(r)
+	conn.Close()
+	s.Close()
+	if got1 != "PONG" || got2 != int64(1) || <-done != ErrServerClosed {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %#v %#v\n\n", filepath.Base(file), line, got1, got2)
+		t.FailNow()
+	}
+}
+
+func BenchmarkGeoSearch(b *testing.B) {
+	s := NewServer()
+	set := newGeoSet()
+	s.sets["k"] = set
+	for i := 0; i < 100000; i++ {
+		lat, lng := float64(i%1000)/1000*10+30, float64(i/1000)/100*10+10
+		score, _ := geohash.RedisEncode(lat, lng)
+		set.add(strconv.Itoa(i), score)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.find(area{lat: 35, lng: 15, radius: 10000})
+	}
+}
@@ -0,0 +1,193 @@
+package resp
+
+import (
+	"math"
+	"sort"
+
+	"github.com/myyang/geohash"
+)
+
+// redis geo constants which are not part of geohash package
+const (
+	earthRadius = 6372797.560856
+	degRad      = math.Pi / 180.0
+	maxCells    = 16
+)
+
+type entry struct {
+	score  uint64
+	member string
+}
+
+// geoSet is a sorted set of members ordered by 52-bit geohash score
+type geoSet struct {
+	scores  map[string]uint64
+	entries []entry
+}
+
+func newGeoSet() *geoSet {
+	return &geoSet{scores: map[string]uint64{}}
+}
+
+func (s *geoSet) search(score uint64, member string) int {
+	return sort.Search(len(s.entries), func(i int) bool {
+		e := s.entries[i]
+		return e.score > score || (e.score == score && e.member >= member)
+	})
+}
+
+// add inserts or updates member, returns whether member is new and whether score changed
+func (s *geoSet) add(member string, score uint64) (added, changed bool) {
+	old, ok := s.scores[member]
+	if ok && old == score {
+		return false, false
+	}
+	if ok {
+		s.remove(member)
+	}
+	s.scores[member] = score
+	i := s.search(score, member)
+	s.entries = append(s.entries, entry{})
+	copy(s.entries[i+1:], s.entries[i:])
+	s.entries[i] = entry{score: score, member: member}
+	return !ok, true
+}
+
+func (s *geoSet) remove(member string) bool {
+	score, ok := s.scores[member]
+	if !ok {
+		return false
+	}
+	delete(s.scores, member)
+	i := s.search(score, member)
+	s.entries = append(s.entries[:i], s.entries[i+1:]...)
+	return true
+}
+
+// scoreRange calls fn for every entry with score in [min, max)
+func (s *geoSet) scoreRange(min, max uint64, fn func(e entry)) {
+	i := sort.Search(len(s.entries), func(i int) bool { return s.entries[i].score >= min })
+	for ; i < len(s.entries) && s.entries[i].score < max; i++ {
+		fn(s.entries[i])
+	}
+}
+
+// area is a search shape centered at (lat, lng), radius or width and height in meters
+type area struct {
+	lat, lng      float64
+	radius        float64
+	width, height float64
+	byBox         bool
+}
+
+// distance returns distance from center to point and whether point lies in area
+func (a area) distance(lat, lng float64) (float64, bool) {
+	if !a.byBox {
+		d := distance(a.lat, a.lng, lat, lng)
+		return d, d <= a.radius
+	}
+	if latDistance(a.lat, lat) > a.height/2 {
+		return 0, false
+	}
+	if distance(lat, a.lng, lat, lng) > a.width/2 {
+		return 0, false
+	}
+	return distance(a.lat, a.lng, lat, lng), true
+}
+
+// bounds returns latitude range and longitude ranges enclosing area,
+// longitude range is split in two when area crosses antimeridian
+func (a area) bounds() (minLat, maxLat float64, lngs [][2]float64) {
+	dlat, dx := a.radius, a.radius
+	if a.byBox {
+		dlat, dx = a.height/2, a.width/2
+	}
+	dlat = dlat / earthRadius / degRad
+	minLat = math.Max(a.lat-dlat, geohash.RedisLatMin)
+	maxLat = math.Min(a.lat+dlat, geohash.RedisLatMax)
+
+	cos := math.Cos(math.Max(math.Abs(minLat), math.Abs(maxLat)) * degRad)
+	dlng := dx / (earthRadius * cos) / degRad
+	if cos <= 0 || dlng >= 180 {
+		return minLat, maxLat, [][2]float64{{geohash.MinLng, geohash.MaxLng}}
+	}
+	minLng, maxLng := a.lng-dlng, a.lng+dlng
+	switch {
+	case minLng < geohash.MinLng:
+		lngs = [][2]float64{{geohash.MinLng, maxLng}, {minLng + 360, geohash.MaxLng}}
+	case maxLng > geohash.MaxLng:
+		lngs = [][2]float64{{minLng, geohash.MaxLng}, {geohash.MinLng, maxLng - 360}}
+	default:
+		lngs = [][2]float64{{minLng, maxLng}}
+	}
+	return minLat, maxLat, lngs
+}
+
+type match struct {
+	member   string
+	score    uint64
+	lat, lng float64
+	dist     float64
+}
+
+// find returns members inside area, candidates are read from score ranges of
+// grid cells covering area bounds
+func (s *geoSet) find(a area) []match {
+	minLat, maxLat, lngs := a.bounds()
+	r := []match{}
+	for _, lng := range lngs {
+		step, cells := cellsOf(minLat, maxLat, lng[0], lng[1])
+		shift := uint(geohash.RedisStep-step) * 2
+		for _, c := range cells {
+			s.scoreRange(c<<shift, (c+1)<<shift, func(e entry) {
+				lat, lng := geohash.RedisDecode(e.score)
+				if d, ok := a.distance(lat, lng); ok {
+					r = append(r, match{member: e.member, score: e.score, lat: lat, lng: lng, dist: d})
+				}
+			})
+		}
+	}
+	return r
+}
+
+// cellsOf returns the finest step at which box is covered by no more than
+// maxCells cells, and the interleaved cell indexes
+func cellsOf(minLat, maxLat, minLng, maxLng float64) (int, []uint64) {
+	index := func(v, min, max float64, step int) uint32 {
+		i := math.Floor((v - min) / (max - min) * float64(uint64(1)<<uint(step)))
+		return uint32(math.Max(0, math.Min(i, float64(uint64(1)<<uint(step)-1))))
+	}
+	step := geohash.RedisStep
+	for ; step > 0; step-- {
+		lat0, lat1 := index(minLat, geohash.RedisLatMin, geohash.RedisLatMax, step), index(maxLat, geohash.RedisLatMin, geohash.RedisLatMax, step)
+		lng0, lng1 := index(minLng, geohash.MinLng, geohash.MaxLng, step), index(maxLng, geohash.MinLng, geohash.MaxLng, step)
+		if (uint64(lat1-lat0)+1)*(uint64(lng1-lng0)+1) > maxCells && step > 1 {
+			continue
+		}
+		cells := []uint64{}
+		for i := lat0; i <= lat1; i++ {
+			for j := lng0; j <= lng1; j++ {
+				cells = append(cells, geohash.Interleave(i, j))
+			}
+		}
+		return step, cells
+	}
+	return 0, []uint64{0}
+}
+
+func latDistance(lat1, lat2 float64) float64 {
+	return earthRadius * math.Abs(lat2*degRad-lat1*degRad)
+}
+
+// distance follows Redis geohashGetDistance
+func distance(lat1, lng1, lat2, lng2 float64) float64 {
+	lng1r, lng2r := lng1*degRad, lng2*degRad
+	v := math.Sin((lng2r - lng1r) / 2)
+	if v == 0 {
+		return latDistance(lat1, lat2)
+	}
+	lat1r, lat2r := lat1*degRad, lat2*degRad
+	u := math.Sin((lat2r - lat1r) / 2)
+	a := u*u + math.Cos(lat1r)*math.Cos(lat2r)*v*v
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
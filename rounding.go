@@ -0,0 +1,81 @@
+package geohash
+
+import "math"
+
+type roundingMode int
+
+const (
+	roundNone roundingMode = iota
+	roundDecimals
+	roundCell
+)
+
+// Rounding selects how decoded coordinates are rounded, independent of hash length.
+// The zero value keeps exact coordinates
+type Rounding struct {
+	mode     roundingMode
+	decimals int
+}
+
+// Rounding presets
+var (
+	// NoRounding keeps exact cell center
+	NoRounding = Rounding{mode: roundNone}
+	// RoundCell keeps as many decimals as cell size gives meaning to,
+	// the rounded center always stays inside its cell
+	RoundCell = Rounding{mode: roundCell}
+)
+
+// RoundDecimals rounds to n decimal places, coarse rounding of small cells
+// may move the center out of its cell
+func RoundDecimals(n int) Rounding {
+	return Rounding{mode: roundDecimals, decimals: n}
+}
+
+// Center returns exact center of rectangle without rounding,
+// boxes crossing the antimeridian are centered on their own side
+func (lb LocationBox) Center() (lat, lng float64) {
+	return (lb.MaxLat + lb.MinLat) / 2, wrapLng(lb.MinLng+lb.Width()/2, false)
+}
+
+// RoundedCenter returns center of rectangle rounded by r
+func (lb LocationBox) RoundedCenter(r Rounding) (lat, lng float64) {
+	lat, lng = lb.Center()
+	switch r.mode {
+	case roundDecimals:
+		return roundDecimal(lat, r.decimals), roundDecimal(lng, r.decimals)
+	case roundCell:
+		return roundDecimal(lat, cellDecimals(lb.MaxLat-lb.MinLat)), roundDecimal(lng, cellDecimals(lb.Width()))
+	}
+	return lat, lng
+}
+
+// DecodeWith decodes center of whole hash value rounded by r
+func DecodeWith(c GeoCryptor, value string, r Rounding) (lat, lng float64) {
+	return c.DecodeAsBox(value, len(value)).(*LocationBox).RoundedCenter(r)
+}
+
+// cellDecimals returns the fewest decimals whose unit is below half of cell size
+func cellDecimals(size float64) int {
+	if size <= 0 {
+		return 0
+	}
+	d := int(math.Ceil(-math.Log10(size / 2)))
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// roundDecimal rounds v to d decimal places, values with more digits than
+// float64 holds are returned as is
+func roundDecimal(v float64, d int) float64 {
+	if v == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return v
+	}
+	if d+int(math.Floor(math.Log10(math.Abs(v))))+1 > 15 {
+		return v
+	}
+	p := math.Pow10(d)
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,52 @@
+package geohash
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestDecodeWith(t *testing.T) {
+	gh, gh36 := NewDefaultGeoHash(), NewDefaultGeoHash36()
+	tr := []struct {
+		C        GeoCryptor
+		Hash     string
+		Rounding Rounding
+		Lat, Lng float64
+	}{
+		{gh, "7", NoRounding, -22.5, -22.5},
+		{gh, "7", RoundCell, -23, -23},
+		{gh, "7ztuee", NoRounding, -2.00225830078125, -3.0047607421875},
+		{gh, "7ztuee", RoundCell, -2.002, -3.005},
+		{gh, "7ztuee", RoundDecimals(1), -2, -3},
+		{gh, "wdhh9b9rv", RoundCell, 12.04511, 118.20386},
+		{gh, "wdhh9b9rv", RoundDecimals(3), 12.045, 118.204},
+		{gh36, "bdrdC26BqH", RoundCell, 51.504444, -0.086666},
+	}
+	for _, v := range tr {
+		lat, lng := DecodeWith(v.C, v.Hash, v.Rounding)
+		if lat != v.Lat || lng != v.Lng {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d: %v\n\n\texp: (%v, %v)\n\n\tgot: (%v, %v)\n\n", filepath.Base(file), line, v.Hash, v.Lat, v.Lng, lat, lng)
+			t.FailNow()
+		}
+	}
+}
+
+func TestRoundedCenterInCell(t *testing.T) {
+	gh, gh36 := NewDefaultGeoHash(), NewDefaultGeoHash36()
+	for _, c := range []GeoCryptor{gh, gh36} {
+		for p := 1; p <= 12; p++ {
+			lb := c.EncodeAsBox(12.04512315, 118.20385763, p).(*LocationBox)
+			for _, r := range []Rounding{NoRounding, RoundCell} {
+				lat, lng := lb.RoundedCenter(r)
+				if lat < lb.MinLat || lat > lb.MaxLat || lng < lb.MinLng || lng > lb.MaxLng {
+					_, file, line, _ := runtime.Caller(0)
+					fmt.Printf("%s:%d: %v\n\n\texp in: %#v\n\n\tgot: (%v, %v)\n\n", filepath.Base(file), line, lb.Hash, lb, lat, lng)
+					t.FailNow()
+				}
+			}
+		}
+	}
+}
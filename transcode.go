@@ -0,0 +1,43 @@
+package geohash
+
+// Transcode returns the minimal set of cells of cryptor `to` at given precision
+// which together cover the cell `value` of cryptor `from`.
+// Cells are ordered from south-west to north-east, row by row.
+func Transcode(from GeoCryptor, value string, to GeoCryptor, precision int) []BoundingBox {
+	if len(value) == 0 || precision <= 0 {
+		return nil
+	}
+	src := from.DecodeAsBox(value, len(value)).(*LocationBox)
+	return cover(to, src, precision)
+}
+
+// TranscodeBest returns the single cell of cryptor `to` at given precision
+// which overlaps the most with cell `value` of cryptor `from`,
+// along with the ratio of the source cell it covers in range (0, 1]
+func TranscodeBest(from GeoCryptor, value string, to GeoCryptor, precision int) (BoundingBox, float64) {
+	if len(value) == 0 || precision <= 0 {
+		return nil, 0
+	}
+	src := from.DecodeAsBox(value, len(value)).(*LocationBox)
+	var best BoundingBox
+	bestRatio := 0.0
+	for _, b := range cover(to, src, precision) {
+		if r := overlapArea(src, b.(*LocationBox)) / boxArea(src); r > bestRatio {
+			best, bestRatio = b, r
+		}
+	}
+	return best, bestRatio
+}
+
+func boxArea(lb *LocationBox) float64 {
+	return (lb.MaxLat - lb.MinLat) * (lb.MaxLng - lb.MinLng)
+}
+
+func overlapArea(a, b *LocationBox) float64 {
+	dlat := minFloat64(a.MaxLat, b.MaxLat) - maxFloat64(a.MinLat, b.MinLat)
+	dlng := minFloat64(a.MaxLng, b.MaxLng) - maxFloat64(a.MinLng, b.MinLng)
+	if dlat <= 0 || dlng <= 0 {
+		return 0
+	}
+	return dlat * dlng
+}
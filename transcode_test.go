@@ -0,0 +1,72 @@
+package geohash
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestTranscode(t *testing.T) {
+	gh, gh36 := NewDefaultGeoHash(), NewDefaultGeoHash36()
+	got, exp := []string{}, []string{}
+
+	for _, v := range Transcode(gh, "9q8yy", gh, 5) {
+		h, _ := v.Geohash()
+		got = append(got, h)
+	}
+	exp = []string{"9q8yy"}
+	if !reflect.DeepEqual(exp, got) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+
+	got = []string{}
+	for _, v := range Transcode(gh, "ez", gh, 3) {
+		h, _ := v.Geohash()
+		got = append(got, h)
+	}
+	if len(got) != 32 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v cells\n\n\tgot: %#v\n\n", filepath.Base(file), line, 32, got)
+		t.FailNow()
+	}
+
+	src := gh36.DecodeAsBox("bdrdC26", 7).(*LocationBox)
+	covered := 0.0
+	got = []string{}
+	for _, v := range Transcode(gh36, "bdrdC26", gh, 7) {
+		h, _ := v.Geohash()
+		got = append(got, h)
+		covered += overlapArea(src, v.(*LocationBox))
+	}
+	exp = []string{"gcpvn00", "gcpvn01"}
+	if !reflect.DeepEqual(exp, got) || math.Abs(covered-boxArea(src)) > boxArea(src)*1e-9 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+}
+
+func TestTranscodeBest(t *testing.T) {
+	gh, gh36 := NewDefaultGeoHash(), NewDefaultGeoHash36()
+
+	b, ratio := TranscodeBest(gh, "9q8yy", gh, 4)
+	h, _ := b.Geohash()
+	if h != "9q8y" || ratio != 1 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: (%#v, %#v)\n\n\tgot: (%#v, %#v)\n\n", filepath.Base(file), line, "9q8y", 1.0, h, ratio)
+		t.FailNow()
+	}
+
+	b, ratio = TranscodeBest(gh36, "bdrdC26", gh, 7)
+	h, _ = b.Geohash()
+	if h != "gcpvn00" || ratio <= 0.5 || ratio >= 1 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: (%#v, (0.5, 1))\n\n\tgot: (%#v, %#v)\n\n", filepath.Base(file), line, "gcpvn00", h, ratio)
+		t.FailNow()
+	}
+}
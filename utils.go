@@ -10,3 +10,56 @@ func roundFloat64(num float64, precision int) float64 {
 	base := math.Pow(10, float64(precision))
 	return float64(round(num*base)) / base
 }
+
+func minFloat64(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxFloat64(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// EarthRadius is mean earth radius in meters
+const EarthRadius = 6371008.8
+
+// Distance returns great-circle distance in meters between two points by haversine formula
+func Distance(lat1, lng1, lat2, lng2 float64) float64 {
+	rad := math.Pi / 180
+	dlat, dlng := (lat2-lat1)*rad, (lng2-lng1)*rad
+	a := math.Pow(math.Sin(dlat/2), 2) + math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Pow(math.Sin(dlng/2), 2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// spread32 moves bit i of v to bit 2i
+func spread32(v uint32) uint64 {
+	x := uint64(v)
+	x = (x | (x << 16)) & 0x0000FFFF0000FFFF
+	x = (x | (x << 8)) & 0x00FF00FF00FF00FF
+	x = (x | (x << 4)) & 0x0F0F0F0F0F0F0F0F
+	x = (x | (x << 2)) & 0x3333333333333333
+	x = (x | (x << 1)) & 0x5555555555555555
+	return x
+}
+
+// Interleave puts bits of lat on even and bits of lng on odd positions,
+// the layout of Redis geohash scores
+func Interleave(lat, lng uint32) uint64 {
+	return spread32(lat) | spread32(lng)<<1
+}
+
+// squash64 collects even bits of v, reverse of spread32
+func squash64(v uint64) uint32 {
+	x := v & 0x5555555555555555
+	x = (x | (x >> 1)) & 0x3333333333333333
+	x = (x | (x >> 2)) & 0x0F0F0F0F0F0F0F0F
+	x = (x | (x >> 4)) & 0x00FF00FF00FF00FF
+	x = (x | (x >> 8)) & 0x0000FFFF0000FFFF
+	x = (x | (x >> 16)) & 0x00000000FFFFFFFF
+	return uint32(x)
+}
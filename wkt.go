@@ -0,0 +1,247 @@
+package geohash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WKB geometry type codes and EWKB flag
+const (
+	wkbPolygon      uint32 = 3
+	wkbMultiPolygon uint32 = 6
+	ewkbSRIDFlag    uint32 = 0x20000000
+	wkbNDR          byte   = 1
+)
+
+// WKT returns box as WKT POLYGON with (lng lat) coordinates,
+// a box crossing the antimeridian is a MULTIPOLYGON of its parts on either side
+func (lb LocationBox) WKT() string {
+	if lb.CrossesAntimeridian() {
+		return MultiPolygonWKT([]BoundingBox{lb})
+	}
+	return "POLYGON" + lb.wktRing()
+}
+
+// WKB returns box as little-endian WKB polygon
+func (lb LocationBox) WKB() []byte {
+	return lb.EWKB(0)
+}
+
+// EWKB returns box as little-endian EWKB polygon tagged with srid, or multipolygon
+// when box crosses the antimeridian. Non-positive srid produces plain WKB
+func (lb LocationBox) EWKB(srid int) []byte {
+	if lb.CrossesAntimeridian() {
+		return MultiPolygonEWKB([]BoundingBox{lb}, srid)
+	}
+	buf := &bytes.Buffer{}
+	writeWKBHeader(buf, wkbPolygon, srid)
+	lb.writeWKBRings(buf)
+	return buf.Bytes()
+}
+
+func (lb LocationBox) wktRing() string {
+	pts := lb.Polygon().Coordinates[0]
+	s := make([]string, 0, len(pts))
+	for _, p := range pts {
+		s = append(s, formatWKTFloat(p[0])+" "+formatWKTFloat(p[1]))
+	}
+	return "((" + strings.Join(s, ", ") + "))"
+}
+
+func (lb LocationBox) writeWKBRings(buf *bytes.Buffer) {
+	pts := lb.Polygon().Coordinates[0]
+	binary.Write(buf, binary.LittleEndian, uint32(1))
+	binary.Write(buf, binary.LittleEndian, uint32(len(pts)))
+	for _, p := range pts {
+		binary.Write(buf, binary.LittleEndian, p[0])
+		binary.Write(buf, binary.LittleEndian, p[1])
+	}
+}
+
+// MultiPolygonWKT returns boxes as WKT MULTIPOLYGON, nil boxes are skipped
+// and boxes crossing the antimeridian are split into two polygons
+func MultiPolygonWKT(boxes []BoundingBox) string {
+	lbs := polygonBoxes(boxes)
+	if len(lbs) == 0 {
+		return "MULTIPOLYGON EMPTY"
+	}
+	s := make([]string, 0, len(lbs))
+	for _, lb := range lbs {
+		s = append(s, lb.wktRing())
+	}
+	return "MULTIPOLYGON(" + strings.Join(s, ", ") + ")"
+}
+
+// MultiPolygonWKB returns boxes as little-endian WKB multipolygon
+func MultiPolygonWKB(boxes []BoundingBox) []byte {
+	return MultiPolygonEWKB(boxes, 0)
+}
+
+// MultiPolygonEWKB returns boxes as little-endian EWKB multipolygon tagged with srid
+func MultiPolygonEWKB(boxes []BoundingBox, srid int) []byte {
+	lbs := polygonBoxes(boxes)
+	buf := &bytes.Buffer{}
+	writeWKBHeader(buf, wkbMultiPolygon, srid)
+	binary.Write(buf, binary.LittleEndian, uint32(len(lbs)))
+	for _, lb := range lbs {
+		writeWKBHeader(buf, wkbPolygon, 0)
+		lb.writeWKBRings(buf)
+	}
+	return buf.Bytes()
+}
+
+func writeWKBHeader(buf *bytes.Buffer, typ uint32, srid int) {
+	buf.WriteByte(wkbNDR)
+	if srid <= 0 {
+		binary.Write(buf, binary.LittleEndian, typ)
+		return
+	}
+	binary.Write(buf, binary.LittleEndian, typ|ewkbSRIDFlag)
+	binary.Write(buf, binary.LittleEndian, uint32(srid))
+}
+
+func formatWKTFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// locationBoxes unwraps boxes and drops nil ones
+func locationBoxes(boxes []BoundingBox) []LocationBox {
+	r := make([]LocationBox, 0, len(boxes))
+	for _, b := range boxes {
+		switch lb := b.(type) {
+		case *LocationBox:
+			if lb != nil {
+				r = append(r, *lb)
+			}
+		case LocationBox:
+			r = append(r, lb)
+		}
+	}
+	return r
+}
+
+// polygonBoxes unwraps boxes, drops nil ones and splits ones crossing the antimeridian
+func polygonBoxes(boxes []BoundingBox) []LocationBox {
+	r := []LocationBox{}
+	for _, lb := range locationBoxes(boxes) {
+		r = append(r, lb.split()...)
+	}
+	return r
+}
+
+// ParseWKTPoint parses WKT or EWKT POINT into latitude, longitude pair for encoding
+func ParseWKTPoint(wkt string) (lat, lng float64, err error) {
+	body, err := wktBody(wkt, "POINT")
+	if err != nil {
+		return 0, 0, err
+	}
+	p, err := parseWKTCoord(body)
+	if err != nil {
+		return 0, 0, WKTError{WKT: wkt, Msg: err.Error()}
+	}
+	return p[1], p[0], nil
+}
+
+// ParseWKTPolygon parses WKT or EWKT POLYGON into polygon,
+// every ring must be closed and contain at least 4 points
+func ParseWKTPolygon(wkt string) (Polygon, error) {
+	body, err := wktBody(wkt, "POLYGON")
+	if err != nil {
+		return Polygon{}, err
+	}
+	p := Polygon{Type: TypePolygon}
+	for len(body) > 0 {
+		if body[0] != '(' {
+			return Polygon{}, WKTError{WKT: wkt, Msg: "expect '(' at ring start"}
+		}
+		end := strings.IndexByte(body, ')')
+		if end < 0 {
+			return Polygon{}, WKTError{WKT: wkt, Msg: "unclosed ring"}
+		}
+		ring := [][2]float64{}
+		for _, c := range strings.Split(body[1:end], ",") {
+			pt, err := parseWKTCoord(c)
+			if err != nil {
+				return Polygon{}, WKTError{WKT: wkt, Msg: err.Error()}
+			}
+			ring = append(ring, pt)
+		}
+		if len(ring) < 4 || ring[0] != ring[len(ring)-1] {
+			return Polygon{}, WKTError{WKT: wkt, Msg: "ring must be closed with at least 4 points"}
+		}
+		p.Coordinates = append(p.Coordinates, ring)
+		body = strings.TrimSpace(body[end+1:])
+		if strings.HasPrefix(body, ",") {
+			body = strings.TrimSpace(body[1:])
+		}
+	}
+	if len(p.Coordinates) == 0 {
+		return Polygon{}, WKTError{WKT: wkt, Msg: "empty polygon"}
+	}
+	return p, nil
+}
+
+// Envelope returns bounding box of polygon outer ring for coverage
+func (p Polygon) Envelope() LocationBox {
+	if len(p.Coordinates) == 0 {
+		return LocationBox{}
+	}
+	lb := LocationBox{MaxLat: math.Inf(-1), MinLat: math.Inf(1), MaxLng: math.Inf(-1), MinLng: math.Inf(1)}
+	for _, pt := range p.Coordinates[0] {
+		lb.MinLng, lb.MaxLng = minFloat64(lb.MinLng, pt[0]), maxFloat64(lb.MaxLng, pt[0])
+		lb.MinLat, lb.MaxLat = minFloat64(lb.MinLat, pt[1]), maxFloat64(lb.MaxLat, pt[1])
+	}
+	lb.LatErr, lb.LngErr = (lb.MaxLat-lb.MinLat)/2, (lb.MaxLng-lb.MinLng)/2
+	return lb
+}
+
+// wktBody strips optional SRID prefix and geometry tag,
+// and returns content inside outermost parentheses
+func wktBody(wkt, tag string) (string, error) {
+	s := strings.TrimSpace(wkt)
+	if strings.HasPrefix(strings.ToUpper(s), "SRID=") {
+		i := strings.IndexByte(s, ';')
+		if i < 0 {
+			return "", WKTError{WKT: wkt, Msg: "missing ';' after SRID"}
+		}
+		s = strings.TrimSpace(s[i+1:])
+	}
+	if !strings.HasPrefix(strings.ToUpper(s), tag) {
+		return "", WKTError{WKT: wkt, Msg: "expect " + tag}
+	}
+	s = strings.TrimSpace(s[len(tag):])
+	if len(s) < 2 || s[0] != '(' || s[len(s)-1] != ')' {
+		return "", WKTError{WKT: wkt, Msg: "expect parenthesized coordinates"}
+	}
+	return strings.TrimSpace(s[1 : len(s)-1]), nil
+}
+
+func parseWKTCoord(s string) ([2]float64, error) {
+	f := strings.Fields(s)
+	if len(f) != 2 {
+		return [2]float64{}, fmt.Errorf("expect 2 coordinates, got %q", s)
+	}
+	x, err := strconv.ParseFloat(f[0], 64)
+	if err != nil {
+		return [2]float64{}, err
+	}
+	y, err := strconv.ParseFloat(f[1], 64)
+	if err != nil {
+		return [2]float64{}, err
+	}
+	return [2]float64{x, y}, nil
+}
+
+// WKTError is returned while parsing malformed WKT
+type WKTError struct {
+	WKT string
+	Msg string
+}
+
+func (we WKTError) Error() string {
+	return fmt.Sprintf("Wrong WKT: %v\n%v", we.Msg, we.WKT)
+}
@@ -0,0 +1,108 @@
+package geohash
+
+import (
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestWKT(t *testing.T) {
+	cryptor := NewDefaultGeoHash()
+	lb := cryptor.DecodeAsBox("7", 1).(*LocationBox)
+
+	got, exp := lb.WKT(), "POLYGON((-45 -45, 0 -45, 0 0, -45 0, -45 -45))"
+	if got != exp {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+
+	got = MultiPolygonWKT([]BoundingBox{lb, cryptor.DecodeAsBox("k", 1), (*LocationBox)(nil)})
+	exp = "MULTIPOLYGON(((-45 -45, 0 -45, 0 0, -45 0, -45 -45)), ((0 -45, 45 -45, 45 0, 0 0, 0 -45)))"
+	if got != exp {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+}
+
+func TestWKB(t *testing.T) {
+	lb := NewDefaultGeoHash().DecodeAsBox("7", 1).(*LocationBox)
+
+	got := hex.EncodeToString(lb.WKB())
+	exp := "0103000000010000000500000000000000008046c000000000008046c0000000000000000000000000008046c000000000000000000000000000000000000000000080" +
+		"46c0000000000000000000000000008046c000000000008046c0"
+	if got != exp {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+
+	got = hex.EncodeToString(lb.EWKB(4326)[:9])
+	exp = "0103000020e6100000"
+	if got != exp {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+
+	got = hex.EncodeToString(MultiPolygonEWKB([]BoundingBox{lb}, 4326)[:22])
+	exp = "0106000020e610000001000000010300000001000000"
+	if got != exp {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+}
+
+func TestParseWKT(t *testing.T) {
+	lat, lng, err := ParseWKTPoint("SRID=4326;POINT(118.20385763 12.04512315)")
+	if err != nil || lat != 12.04512315 || lng != 118.20385763 {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: (%v, %v)\n\n\tgot: (%v, %v, %v)\n\n", filepath.Base(file), line, 12.04512315, 118.20385763, lat, lng, err)
+		t.FailNow()
+	}
+
+	p, err := ParseWKTPolygon("polygon ((-45 -45, 0 -45, 0 0, -45 0, -45 -45))")
+	exp := NewDefaultGeoHash().DecodeAsBox("7", 1).(*LocationBox).Polygon()
+	if err != nil || !reflect.DeepEqual(exp, p) {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v, %v\n\n", filepath.Base(file), line, exp, p, err)
+		t.FailNow()
+	}
+
+	for _, v := range []string{"POINT(1)", "LINESTRING(1 2, 3 4)", "POLYGON((0 0, 1 0, 1 1))", "POLYGON((0 0, 1 0, 1 1, 0 0)"} {
+		if _, err := ParseWKTPolygon(v); err == nil {
+			_, file, line, _ := runtime.Caller(0)
+			fmt.Printf("%s:%d:\n\n\texp error for %#v\n\n", filepath.Base(file), line, v)
+			t.FailNow()
+		}
+	}
+}
+
+func TestAntimeridianWKT(t *testing.T) {
+	pacific := LocationBox{MinLat: -10, MinLng: 170, MaxLat: 10, MaxLng: -170}
+	exp := "MULTIPOLYGON(((170 -10, 180 -10, 180 10, 170 10, 170 -10)), ((-180 -10, -170 -10, -170 10, -180 10, -180 -10)))"
+	if got := pacific.WKT(); got != exp {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+	exp = "MULTIPOLYGON(((-45 -45, 0 -45, 0 0, -45 0, -45 -45)), " + exp[len("MULTIPOLYGON("):]
+	if got := MultiPolygonWKT([]BoundingBox{NewDefaultGeoHash().DecodeAsBox("7", 1), &pacific}); got != exp {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\texp: %#v\n\n\tgot: %#v\n\n", filepath.Base(file), line, exp, got)
+		t.FailNow()
+	}
+
+	// multipolygon header with two polygons, each of one ring of 5 points
+	got := hex.EncodeToString(pacific.EWKB(4326))
+	if len(got) != 2*(13+2*(9+4+5*16)) || got[:26] != "0106000020e610000002000000" {
+		_, file, line, _ := runtime.Caller(0)
+		fmt.Printf("%s:%d:\n\n\tgot: %#v\n\n", filepath.Base(file), line, got)
+		t.FailNow()
+	}
+}